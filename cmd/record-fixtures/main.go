@@ -0,0 +1,28 @@
+// record-fixtures graba las salidas de los comandos de Windows del equipo
+// actual en una carpeta con el formato de core/testdata/commands, para
+// ampliar el corpus que verifica go test. No forma parte del relevamiento.
+//
+// Uso: record-fixtures DIR
+package main
+
+import (
+	"fmt"
+	"os"
+	"relevamiento/core"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "Uso: record-fixtures DIR")
+		os.Exit(2)
+	}
+	dir := os.Args[1]
+
+	if _, err := core.RecordFixtures(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[OK] Salidas grabadas en %s\n", dir)
+	fmt.Printf("     Revise %s antes de agregarlo al corpus\n", core.ExpectedFixtureFile)
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"relevamiento/core"
+	"strings"
+)
+
+// version se completa al compilar con -ldflags "-X main.version=..."
+var version = "dev"
+
+// cliOptions son las opciones comunes a todos los comandos.
+type cliOptions struct {
+	unattended bool
+	output     string
+	logLevel   string
+	logFormat  string
+
+	skipPreflight   bool
+	ignorePreflight bool
+}
+
+var options = cliOptions{output: outputText}
+
+// commandEnv es el .env que necesita el comando en curso. Se carga recien
+// despues de leer sus opciones, para que --help funcione sin .env.
+var commandEnv = envNone
+
+// Como usa cada comando el .env.
+const (
+	envNone     = iota
+	envOptional // lo carga si existe: comandos que leen configuracion
+	envRequired // valida el entorno y exige el .env
+)
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	env     int
+	run     func(args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{name: "capture", args: "[--piso P] [--oficina O] [--dry-run] [--no-preflight] [--ignore-preflight]", summary: "Releva el equipo y lo guarda en la base", env: envRequired, run: runCapture},
+		{name: "configure", args: "--piso P --oficina O", summary: "Guarda la ubicacion usada por capture", run: runConfigure},
+		{name: "show-config", summary: "Muestra la ubicacion guardada", run: runShowConfig},
+		{name: "reset-config", summary: "Elimina la ubicacion guardada", run: runResetConfig},
+		{name: "preflight", summary: "Verifica cable, IP, gateway, DNS y conexion a la base", env: envRequired, run: runPreflightCommand},
+		{name: "sync", summary: "Reenvia las capturas pendientes del spool", env: envRequired, run: runSync},
+		{name: "adapters", summary: "Lista los adaptadores de red detectados", env: envOptional, run: runAdapters},
+		{name: "explain-adapters", args: "[--rules FILE]", summary: "Muestra que regla clasifico a cada adaptador", env: envOptional, run: runExplainAdapters},
+		{name: "system-info", summary: "Muestra la informacion de sistema y BIOS", env: envOptional, run: runSystemInfo},
+		{name: "find-mac", args: "MAC", summary: "Busca una MAC entre todos los adaptadores capturados", env: envRequired, run: runFindMac},
+		{name: "report-dominio", summary: "Resumen de equipos por piso segun dominio", env: envRequired, run: runReportDominio},
+		{name: "migrate", args: "up|down [N]|status", summary: "Administra las migraciones de la base", env: envRequired, run: runMigrateCommand},
+		{name: "version", summary: "Muestra la version", run: runVersion},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// newFlagSet crea el FlagSet de un comando con las opciones comunes ya
+// registradas, para que puedan ir antes o despues del comando.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&options.unattended, "unattended", options.unattended, "no pedir nada por consola (GPO, tareas programadas)")
+	fs.StringVar(&options.output, "output", options.output, "formato de salida: text, json o csv")
+	fs.StringVar(&options.logLevel, "log-level", options.logLevel, "nivel de log: debug, info, warning o error (LOG_LEVEL)")
+	fs.StringVar(&options.logFormat, "log-format", options.logFormat, "formato del log: text o json (LOG_FORMAT)")
+	fs.Usage = func() {}
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	fs.SetOutput(new(strings.Builder))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printFlagUsage(fs)
+			return err
+		}
+		return usageErrorf("%v", err)
+	}
+	if err := loadCommandEnvironment(); err != nil {
+		return err
+	}
+	if err := applyLogOptions(); err != nil {
+		return err
+	}
+	return setupOutput()
+}
+
+// printFlagUsage muestra en stderr la ayuda de fs con los valores por
+// defecto de sus opciones.
+func printFlagUsage(fs *flag.FlagSet) {
+	if cmd, ok := findCommand(fs.Name()); ok {
+		fmt.Fprintln(os.Stderr, strings.TrimSpace("Uso: relevamiento "+cmd.name+" "+cmd.args))
+		fmt.Fprintf(os.Stderr, "\n%s\n", cmd.summary)
+	} else {
+		printUsage()
+	}
+	fmt.Fprintln(os.Stderr, "\nOpciones:")
+	fs.SetOutput(os.Stderr)
+	fs.PrintDefaults()
+}
+
+// loadCommandEnvironment carga el .env que pide el comando en curso, una
+// sola vez.
+func loadCommandEnvironment() error {
+	env := commandEnv
+	commandEnv = envNone
+
+	switch env {
+	case envRequired:
+		return loadEnvironment()
+	case envOptional:
+		return loadOptionalEnvironment()
+	}
+	return nil
+}
+
+func run(args []string) (code int) {
+	initLogging()
+	defer closeLogging()
+
+	commandEnv = envNone
+	global := newFlagSet("relevamiento")
+	if err := parseFlags(global, args); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			printUsage()
+		}
+		return reportError(err)
+	}
+	args = global.Args()
+
+	interactive := len(args) == 0 && !options.unattended
+
+	defer handlePanic(&code, interactive)
+
+	if len(args) == 0 {
+		if options.unattended || options.output != outputText {
+			printUsage()
+			return reportError(usageErrorf("--unattended y --output requieren un comando"))
+		}
+
+		logInfo("Iniciando relevamiento...")
+		printBanner()
+
+		err := loadEnvironment()
+		if err == nil {
+			err = showMenu()
+		}
+		code = reportError(err)
+		waitForExit()
+		return code
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		printUsage()
+		return reportError(usageErrorf("comando desconocido: %s", args[0]))
+	}
+
+	logInfo(fmt.Sprintf("Iniciando relevamiento: comando %s", cmd.name))
+
+	commandEnv = cmd.env
+	return reportError(cmd.run(args[1:]))
+}
+
+// reportError muestra el error al usuario y devuelve el codigo de salida.
+func reportError(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	code := exitCode(err)
+	if errorKindOf(err) == kindSpooled {
+		logWarning(fmt.Sprintf("Finalizado con captura pendiente (codigo %d)", code))
+		fmt.Fprintf(os.Stderr, "[!] %v\n", err)
+		return code
+	}
+
+	logError(fmt.Sprintf("Finalizado con error (codigo %d)", code), err)
+	fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+	return code
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Uso: relevamiento [--unattended] [--output text|json|csv] [--log-level L] [--log-format text|json] [comando] [opciones]")
+	fmt.Fprintln(os.Stderr, "\nSin comando se abre el menu interactivo.")
+	fmt.Fprintln(os.Stderr, "\nComandos:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-15s %-38s %s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nCodigos de salida:")
+	fmt.Fprintf(os.Stderr, "  %2d  OK\n", exitOK)
+	for _, kind := range exitCodeOrder {
+		info := errorKinds[kind]
+		fmt.Fprintf(os.Stderr, "  %2d  %s\n", info.code, info.message)
+	}
+}
+
+func runCapture(args []string) error {
+	fs := newFlagSet("capture")
+	piso := fs.String("piso", "", "piso (por defecto el de la configuracion guardada)")
+	oficina := fs.String("oficina", "", "oficina (por defecto la de la configuracion guardada)")
+	dryRun := fs.Bool("dry-run", false, "detectar y mostrar el registro sin guardarlo")
+	fs.BoolVar(&options.skipPreflight, "no-preflight", false, "no ejecutar el diagnostico de red antes de guardar")
+	fs.BoolVar(&options.ignorePreflight, "ignore-preflight", false, "guardar aunque el diagnostico de red encuentre fallas")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	config, err := core.LoadLocationConfig()
+	if err != nil {
+		return newError(kindConfig, err)
+	}
+	if config == nil {
+		config = &core.LocationConfig{}
+	}
+	if *piso != "" {
+		config.Piso = *piso
+	}
+	if *oficina != "" {
+		config.Oficina = *oficina
+	}
+
+	if config.Oficina == "" && !*dryRun && !core.HasLocationMap() {
+		return errorf(kindConfig, "no hay ubicacion configurada: use 'configure' o --piso/--oficina")
+	}
+
+	if *dryRun {
+		return executeDryRun(config.Piso, config.Oficina)
+	}
+	return executeCapture(config.Piso, config.Oficina)
+}
+
+func runConfigure(args []string) error {
+	fs := newFlagSet("configure")
+	piso := fs.String("piso", "", "piso (por defecto '0')")
+	oficina := fs.String("oficina", "", "oficina (obligatoria)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *piso == "" && *oficina == "" {
+		if options.unattended {
+			return usageErrorf("--unattended requiere --oficina")
+		}
+		return configureOnly()
+	}
+
+	if *oficina == "" {
+		return usageErrorf("--oficina es obligatoria")
+	}
+	if *piso == "" {
+		*piso = "0"
+	}
+
+	return saveLocation(*piso, *oficina)
+}
+
+func runShowConfig(args []string) error {
+	if err := parseFlags(newFlagSet("show-config"), args); err != nil {
+		return err
+	}
+
+	config, err := core.LoadLocationConfig()
+	if err != nil {
+		return newError(kindConfig, err)
+	}
+	if config == nil {
+		return errorf(kindConfig, "sin configuracion guardada en %s", core.GetConfigFilePath())
+	}
+
+	return emit(configRecord(config, core.GetConfigFilePath()), func() {
+		fmt.Printf("Piso:    %s\n", config.Piso)
+		fmt.Printf("Oficina: %s\n", config.Oficina)
+		fmt.Printf("Archivo: %s\n", core.GetConfigFilePath())
+	})
+}
+
+func runResetConfig(args []string) error {
+	if err := parseFlags(newFlagSet("reset-config"), args); err != nil {
+		return err
+	}
+
+	existia := core.HasLocationConfig()
+	if err := core.DeleteLocationConfig(); err != nil {
+		return newError(kindConfig, err)
+	}
+
+	path := core.GetConfigFilePath()
+	if existia {
+		logInfo("Configuracion eliminada")
+	}
+	return emit(resetRecord(path, existia), func() {
+		if existia {
+			fmt.Println("[OK] Configuracion eliminada")
+		} else {
+			fmt.Printf("[OK] No habia configuracion guardada en %s\n", path)
+		}
+	})
+}
+
+func runAdapters(args []string) error {
+	if err := parseFlags(newFlagSet("adapters"), args); err != nil {
+		return err
+	}
+
+	adapters := core.GetAllNetworkAdapters()
+	records := make([]record, 0, len(adapters))
+	for _, a := range adapters {
+		records = append(records, adapterRecord(a))
+	}
+
+	return emitList(records, adapterRecord(core.NetworkAdapter{}), func() {
+		if len(adapters) == 0 {
+			fmt.Println("[!] No se detectaron adaptadores de red")
+			return
+		}
+		fmt.Printf("\n%-30s %-19s %-8s %-6s %s\n", "Nombre", "MAC", "Ethernet", "Activo", "Estado")
+		for _, a := range adapters {
+			fmt.Printf("%-30s %-19s %-8s %-6s %s\n", a.Name, a.MacAddress, textValue(a.IsEthernet), textValue(a.IsActive), a.Status)
+		}
+	})
+}
+
+func runExplainAdapters(args []string) error {
+	fs := newFlagSet("explain-adapters")
+	rulesFile := fs.String("rules", "", "archivo de reglas JSON o YAML (ADAPTER_RULES_FILE)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := loadAdapterRules(*rulesFile); err != nil {
+		return err
+	}
+
+	_, source := core.ActiveAdapterRules()
+	if source == "" {
+		source = "reglas predeterminadas"
+	}
+
+	adapters := core.GetAllNetworkAdapters()
+	records := make([]record, 0, len(adapters))
+	for _, a := range adapters {
+		records = append(records, explainRecord(a, core.ClassifyAdapter(a)))
+	}
+
+	return emitList(records, explainRecord(core.NetworkAdapter{}, core.RuleDecision{}), func() {
+		fmt.Printf("\nReglas: %s\n", source)
+		if len(adapters) == 0 {
+			fmt.Println("[!] No se detectaron adaptadores de red")
+			return
+		}
+		fmt.Printf("\n%-30s %-19s %-8s %s\n", "Nombre", "MAC", "Accion", "Regla")
+		for _, a := range adapters {
+			d := core.ClassifyAdapter(a)
+			accion := core.RuleExclude
+			if d.Include {
+				accion = core.RuleInclude
+			}
+			regla := d.Rule
+			if d.Index > 0 {
+				regla = fmt.Sprintf("#%d %s", d.Index, d.Rule)
+			}
+			fmt.Printf("%-30s %-19s %-8s %s\n", a.Name, a.MacAddress, accion, regla)
+		}
+	})
+}
+
+func runSystemInfo(args []string) error {
+	if err := parseFlags(newFlagSet("system-info"), args); err != nil {
+		return err
+	}
+
+	info, report := core.GetSystemInfoReport()
+	serialNumber, biosVersion := core.GetBIOSInfo()
+	r := systemRecord(info, report, core.NormalizeSerialNumber(serialNumber), biosVersion)
+
+	return emit(r, func() {
+		printRecordText("       INFORMACION DEL SISTEMA", r)
+		for _, u := range report.Unresolved {
+			fmt.Printf("[!] Sin resolver: %s - %s\n", u.Field, u.Reason)
+		}
+	})
+}
+
+func runPreflightCommand(args []string) error {
+	if err := parseFlags(newFlagSet("preflight"), args); err != nil {
+		return err
+	}
+
+	checks := runPreflight(nil)
+	err := emitList(preflightRecords(checks), preflightRecord(core.PreflightCheck{}), func() { printPreflight(checks) })
+	if err != nil {
+		return err
+	}
+
+	if core.PreflightFailed(checks) {
+		return errorf(kindPreflight, "revise los puntos marcados con [X]")
+	}
+	return nil
+}
+
+func runSync(args []string) error {
+	if err := parseFlags(newFlagSet("sync"), args); err != nil {
+		return err
+	}
+	return syncSpool()
+}
+
+func runReportDominio(args []string) error {
+	if err := parseFlags(newFlagSet("report-dominio"), args); err != nil {
+		return err
+	}
+	return reportDominio()
+}
+
+func runFindMac(args []string) error {
+	macAddress := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		macAddress, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("find-mac")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if macAddress == "" && fs.NArg() > 0 {
+		macAddress = fs.Arg(0)
+	}
+	if macAddress == "" {
+		return usageErrorf("find-mac requiere una MAC")
+	}
+	return findMac(macAddress)
+}
+
+func runMigrateCommand(args []string) error {
+	fs := newFlagSet("migrate")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return runMigrate(fs.Args())
+}
+
+func runVersion(args []string) error {
+	if err := parseFlags(newFlagSet("version"), args); err != nil {
+		return err
+	}
+	return emit(record{{"version", "Version", version}}, func() {
+		fmt.Printf("relevamiento %s\n", version)
+	})
+}
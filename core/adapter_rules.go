@@ -0,0 +1,239 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	RuleInclude = "include"
+	RuleExclude = "exclude"
+)
+
+// AdapterRule es una regla de clasificacion. Las reglas se evaluan en orden
+// y gana la primera cuyo Match coincide: include marca el adaptador como
+// Ethernet del relevamiento y exclude lo descarta.
+type AdapterRule struct {
+	Name   string       `json:"name" yaml:"name"`
+	Action string       `json:"action" yaml:"action"`
+	Match  AdapterMatch `json:"match" yaml:"match"`
+}
+
+// AdapterMatch combina condiciones con Y; dentro de una lista alcanza con que
+// coincida un valor. Name, Description y Vendor comparan por subcadena sin
+// distinguir mayusculas; Vendor "*" es cualquier fabricante conocido.
+// MacPrefix admite cualquier notacion de MAC (00-15-5D, 00:15:5d, 00155D).
+type AdapterMatch struct {
+	Name                []string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description         []string `json:"description,omitempty" yaml:"description,omitempty"`
+	Vendor              []string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	MacPrefix           []string `json:"mac_prefix,omitempty" yaml:"mac_prefix,omitempty"`
+	HardwareType        []string `json:"hardware_type,omitempty" yaml:"hardware_type,omitempty"`
+	Source              []string `json:"source,omitempty" yaml:"source,omitempty"`
+	Physical            *bool    `json:"physical,omitempty" yaml:"physical,omitempty"`
+	LocallyAdministered *bool    `json:"locally_administered,omitempty" yaml:"locally_administered,omitempty"`
+}
+
+type adapterRulesFile struct {
+	Rules []AdapterRule `json:"rules" yaml:"rules"`
+}
+
+// RuleDecision es el resultado de clasificar un adaptador.
+type RuleDecision struct {
+	Include bool
+	Rule    string
+	Index   int
+}
+
+// NoRuleMatched se informa cuando ninguna regla coincide; el adaptador se
+// excluye.
+const NoRuleMatched = "sin regla (excluido por defecto)"
+
+var (
+	rulesMu       sync.RWMutex
+	adapterRules  = DefaultAdapterRules()
+	rulesFilePath string
+)
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// DefaultAdapterRules es la clasificacion que se usa si no hay archivo de
+// reglas: se descartan inalambricos, virtuales y VPN, y se acepta lo que
+// se identifica como Ethernet.
+func DefaultAdapterRules() []AdapterRule {
+	return []AdapterRule{
+		{Name: "hardware-no-cableado", Action: RuleExclude, Match: AdapterMatch{
+			HardwareType: []string{"wireless", "loopback", "tunnel", "ppp", "bridge", "virtual"},
+		}},
+		{Name: "mac-de-virtualizacion", Action: RuleExclude, Match: AdapterMatch{
+			MacPrefix: []string{"00-50-56", "00-0C-29", "00-05-69", "00-1C-14", "00-15-5D", "08-00-27", "00-1C-42", "00-16-3E", "0A-00-27", "52-54-00", "02-42"},
+		}},
+		{Name: "inalambrico", Action: RuleExclude, Match: AdapterMatch{
+			Name: []string{"wi-fi", "wireless", "wlan", "802.11", "inalámbrica", "inalambrica", "bluetooth"},
+		}},
+		{Name: "inalambrico-descripcion", Action: RuleExclude, Match: AdapterMatch{
+			Description: []string{"wi-fi", "wireless", "wlan", "802.11", "inalámbrica", "inalambrica", "bluetooth"},
+		}},
+		{Name: "virtual-o-vpn", Action: RuleExclude, Match: AdapterMatch{
+			Name: []string{"vmware", "virtualbox", "hyper-v", "vpn"},
+		}},
+		{Name: "virtual-o-vpn-descripcion", Action: RuleExclude, Match: AdapterMatch{
+			Description: []string{"virtual", "vpn", "fortinet", "tap-windows", "wireguard"},
+		}},
+		{Name: "nombre-ethernet", Action: RuleInclude, Match: AdapterMatch{
+			Name: []string{"ethernet"},
+		}},
+		{Name: "descripcion-ethernet", Action: RuleInclude, Match: AdapterMatch{
+			Description: []string{"ethernet"},
+		}},
+		{Name: "fisico-ethernet", Action: RuleInclude, Match: AdapterMatch{
+			HardwareType: []string{"ethernet"},
+			Physical:     boolPtr(true),
+		}},
+		{Name: "getmac-fabricante-conocido", Action: RuleInclude, Match: AdapterMatch{
+			Source:              []string{AdapterSourceGetmac},
+			Vendor:              []string{"*"},
+			LocallyAdministered: boolPtr(false),
+		}},
+	}
+}
+
+// LoadAdapterRules reemplaza las reglas activas por las de un archivo JSON o
+// YAML (segun la extension). Un archivo sin reglas es un error, para no
+// excluir todos los adaptadores por accidente.
+func LoadAdapterRules(path string) error {
+	var file adapterRulesFile
+	if err := loadConfigFile(path, &file); err != nil {
+		return fmt.Errorf("error leyendo reglas de adaptadores: %w", err)
+	}
+
+	if len(file.Rules) == 0 {
+		return fmt.Errorf("el archivo %s no tiene reglas", path)
+	}
+	for i, rule := range file.Rules {
+		if rule.Action != RuleInclude && rule.Action != RuleExclude {
+			return fmt.Errorf("regla %d (%s): accion invalida %q (use include o exclude)", i+1, rule.Name, rule.Action)
+		}
+		for _, prefix := range rule.Match.MacPrefix {
+			if _, err := parseMacPrefix(prefix); err != nil {
+				return fmt.Errorf("regla %d (%s): %v", i+1, rule.Name, err)
+			}
+		}
+	}
+
+	rulesMu.Lock()
+	adapterRules = file.Rules
+	rulesFilePath = path
+	rulesMu.Unlock()
+	return nil
+}
+
+// ActiveAdapterRules devuelve las reglas en uso y el archivo del que salieron
+// ("" si son las predeterminadas).
+func ActiveAdapterRules() ([]AdapterRule, string) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return adapterRules, rulesFilePath
+}
+
+// ClassifyAdapter aplica las reglas activas al adaptador.
+func ClassifyAdapter(adapter NetworkAdapter) RuleDecision {
+	rules, _ := ActiveAdapterRules()
+
+	for i, rule := range rules {
+		if rule.Match.matches(adapter) {
+			return RuleDecision{
+				Include: rule.Action == RuleInclude,
+				Rule:    rule.Name,
+				Index:   i + 1,
+			}
+		}
+	}
+
+	return RuleDecision{Rule: NoRuleMatched}
+}
+
+func (m AdapterMatch) matches(adapter NetworkAdapter) bool {
+	if len(m.Name) > 0 && !containsAny(adapter.Name, m.Name) {
+		return false
+	}
+	if len(m.Description) > 0 && !containsAny(adapter.AdapterType, m.Description) {
+		return false
+	}
+	if len(m.Vendor) > 0 && !matchesVendor(adapter.Vendor, m.Vendor) {
+		return false
+	}
+	if len(m.MacPrefix) > 0 && !matchesMacPrefix(adapter.MacAddress, m.MacPrefix) {
+		return false
+	}
+	if len(m.HardwareType) > 0 && !equalsAny(adapter.HardwareType, m.HardwareType) {
+		return false
+	}
+	if len(m.Source) > 0 && !equalsAny(adapter.Source, m.Source) {
+		return false
+	}
+	if m.Physical != nil && *m.Physical != adapter.Physical {
+		return false
+	}
+	if m.LocallyAdministered != nil && *m.LocallyAdministered != adapter.LocallyAdministered {
+		return false
+	}
+	return true
+}
+
+func containsAny(value string, patterns []string) bool {
+	value = strings.ToLower(value)
+	for _, p := range patterns {
+		if p != "" && strings.Contains(value, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalsAny(value string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.EqualFold(value, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesVendor(vendor string, patterns []string) bool {
+	for _, p := range patterns {
+		if p == "*" && vendor != "" {
+			return true
+		}
+	}
+	return containsAny(vendor, patterns)
+}
+
+func matchesMacPrefix(mac string, prefixes []string) bool {
+	hex := strings.ReplaceAll(NormalizeMac(mac), "-", "")
+	for _, p := range prefixes {
+		prefix, err := parseMacPrefix(p)
+		if err == nil && strings.HasPrefix(hex, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMacPrefix lleva un prefijo en cualquier notacion a hexadecimal en
+// mayusculas sin separadores.
+func parseMacPrefix(prefix string) (string, error) {
+	hex := strings.ToUpper(strings.NewReplacer("-", "", ":", "", ".", "").Replace(strings.TrimSpace(prefix)))
+	if hex == "" || len(hex) > 12 {
+		return "", fmt.Errorf("prefijo de MAC invalido: %q", prefix)
+	}
+	for i := 0; i < len(hex); i++ {
+		if _, ok := hexValue(hex[i]); !ok {
+			return "", fmt.Errorf("prefijo de MAC invalido: %q", prefix)
+		}
+	}
+	return hex, nil
+}
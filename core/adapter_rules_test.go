@@ -0,0 +1,117 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// restoreAdapterRules vuelve a las reglas activas al terminar el test.
+func restoreAdapterRules(t *testing.T) {
+	t.Helper()
+
+	rules, path := ActiveAdapterRules()
+	t.Cleanup(func() {
+		rulesMu.Lock()
+		adapterRules, rulesFilePath = rules, path
+		rulesMu.Unlock()
+	})
+}
+
+func TestClassifyAdapterDefaultRules(t *testing.T) {
+	restoreAdapterRules(t)
+	rulesMu.Lock()
+	adapterRules, rulesFilePath = DefaultAdapterRules(), ""
+	rulesMu.Unlock()
+
+	tests := []struct {
+		name    string
+		adapter NetworkAdapter
+		include bool
+		rule    string
+	}{
+		{"Ethernet por nombre", NetworkAdapter{Name: "Ethernet", AdapterType: "Intel(R) Ethernet Connection I219-LM", MacAddress: "00-02-B3-11-22-33"}, true, "nombre-ethernet"},
+		{"Ethernet por descripcion", NetworkAdapter{Name: "LAN", AdapterType: "Realtek PCIe GbE Family Controller Ethernet"}, true, "descripcion-ethernet"},
+		{"Linux fisico", NetworkAdapter{Name: "enp3s0", HardwareType: "ethernet", Physical: true}, true, "fisico-ethernet"},
+		{"Linux sin dispositivo", NetworkAdapter{Name: "enp3s0", HardwareType: "ethernet"}, false, NoRuleMatched},
+		{"inalambrico por hardware", NetworkAdapter{Name: "wlp2s0", HardwareType: "wireless"}, false, "hardware-no-cableado"},
+		{"Wi-Fi", NetworkAdapter{Name: "Wi-Fi", AdapterType: "Intel(R) Wi-Fi 6 AX201"}, false, "inalambrico"},
+		{"WLAN en aleman", NetworkAdapter{Name: "WLAN", AdapterType: "Realtek RTL8821CE 802.11ac PCIe Adapter"}, false, "inalambrico"},
+		{"Hyper-V con nombre Ethernet", NetworkAdapter{Name: "vEthernet (Default Switch)", AdapterType: "Hyper-V Virtual Ethernet Adapter", MacAddress: "00-15-5D-01-02-03"}, false, "mac-de-virtualizacion"},
+		{"VPN con descripcion Ethernet", NetworkAdapter{Name: "Ethernet 3", AdapterType: "Fortinet Virtual Ethernet Adapter (NDIS 6.30)"}, false, "virtual-o-vpn-descripcion"},
+		{"Docker", NetworkAdapter{Name: "docker0", MacAddress: "02:42:ac:11:00:02"}, false, "mac-de-virtualizacion"},
+		{"getmac con fabricante", NetworkAdapter{Name: "Conexion de area local", Source: AdapterSourceGetmac, Vendor: "Intel Corporate"}, true, "getmac-fabricante-conocido"},
+		{"getmac MAC local", NetworkAdapter{Name: "Conexion de area local", Source: AdapterSourceGetmac, Vendor: "Intel Corporate", LocallyAdministered: true}, false, NoRuleMatched},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyAdapter(tt.adapter)
+			if got.Include != tt.include || got.Rule != tt.rule {
+				t.Errorf("ClassifyAdapter() = %v por %q, se esperaba %v por %q", got.Include, got.Rule, tt.include, tt.rule)
+			}
+		})
+	}
+}
+
+func TestLoadAdapterRules(t *testing.T) {
+	restoreAdapterRules(t)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"yaml", "reglas.yaml", "rules:\n  - {name: dock, action: include, match: {mac_prefix: [\"00:E0:4C\"]}}\n  - {name: getmac, action: exclude, match: {source: [getmac]}}\n", ""},
+		{"json", "reglas.json", `{"rules": [{"name": "dock", "action": "include", "match": {"mac_prefix": ["00E04C"]}}]}`, ""},
+		{"sin reglas", "vacio.yaml", "rules: []\n", "no tiene reglas"},
+		{"accion invalida", "accion.yaml", "rules:\n  - {name: x, action: permitir}\n", "accion invalida"},
+		{"prefijo invalido", "prefijo.yaml", "rules:\n  - {name: x, action: include, match: {mac_prefix: [\"00-ZZ\"]}}\n", "prefijo de MAC invalido"},
+		{"formato invalido", "roto.json", "{rules", "roto.json invalido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := LoadAdapterRules(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadAdapterRules() = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, active := ActiveAdapterRules(); active != path {
+				t.Errorf("archivo activo = %q, se esperaba %q", active, path)
+			}
+			got := ClassifyAdapter(NetworkAdapter{Name: "Ethernet 2", MacAddress: "00-E0-4C-68-01-02"})
+			if !got.Include || got.Rule != "dock" || got.Index != 1 {
+				t.Errorf("ClassifyAdapter() = %+v, se esperaba la regla dock", got)
+			}
+		})
+	}
+}
+
+// TestAdapterRulesExample evita que el ejemplo documentado se aparte de las
+// reglas predeterminadas.
+func TestAdapterRulesExample(t *testing.T) {
+	restoreAdapterRules(t)
+
+	if err := LoadAdapterRules(filepath.Join("..", "adapter_rules.example.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ActiveAdapterRules()
+	if want := DefaultAdapterRules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("adapter_rules.example.yaml no coincide con DefaultAdapterRules:\n%+v\n%+v", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	AdapterSourceNative = "native"
+	AdapterSourceGetmac = "getmac"
+)
+
+// linkDetails son los datos del enlace que net.Interfaces no expone y se
+// obtienen de cada sistema operativo (sysfs en Linux, GetAdaptersInfo en
+// Windows).
+type linkDetails struct {
+	Description  string
+	HardwareType string
+	Speed        string
+	SpeedMbps    int64
+	Duplex       string
+	OperState    string
+	Physical     bool
+	Gateway      string
+}
+
+// GetAllNetworkAdapters enumera los adaptadores con net.Interfaces y los
+// detalles de enlace del sistema. Si no se obtiene ninguno se recurre a
+// getmac, que devuelve el mismo NetworkAdapter.
+func GetAllNetworkAdapters() []NetworkAdapter {
+	adapters, err := getNativeAdapters()
+	if err == nil && len(adapters) > 0 {
+		return adapters
+	}
+
+	return getGetmacAdapters(currentRunner())
+}
+
+func getNativeAdapters() ([]NetworkAdapter, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("error enumerando interfaces: %v", err)
+	}
+
+	details := loadLinkDetails(ifaces)
+	adapters := []NetworkAdapter{}
+
+	for _, iface := range ifaces {
+		mac, ok := MACFromHardwareAddr(iface.HardwareAddr)
+		if iface.Flags&net.FlagLoopback != 0 || !ok {
+			continue
+		}
+
+		link := details[iface.Index]
+		adapter := NetworkAdapter{
+			Name:         iface.Name,
+			AdapterType:  link.Description,
+			MacAddress:   mac.String(),
+			Speed:        link.Speed,
+			SpeedMbps:    link.SpeedMbps,
+			Duplex:       link.Duplex,
+			Gateway:      link.Gateway,
+			Index:        iface.Index,
+			MTU:          iface.MTU,
+			Flags:        iface.Flags.String(),
+			HardwareType: link.HardwareType,
+			Source:       AdapterSourceNative,
+		}
+
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				adapter.Addresses = append(adapter.Addresses, addr.String())
+
+				ipnet, ok := addr.(*net.IPNet)
+				if !ok {
+					continue
+				}
+				if ipnet.IP.To4() != nil {
+					adapter.IPv4 = append(adapter.IPv4, ipnet.IP.String())
+				} else {
+					adapter.IPv6 = append(adapter.IPv6, ipnet.IP.String())
+				}
+			}
+			adapter.IPAddress = PreferredAddress(adapter).IP
+		}
+
+		up := iface.Flags&net.FlagUp != 0
+		running := iface.Flags&net.FlagRunning != 0
+		if link.OperState != "" {
+			running = running && link.OperState != "down"
+		}
+		adapter.IsActive = up && running
+
+		switch {
+		case !up:
+			adapter.Status = "Deshabilitado"
+		case !adapter.IsActive:
+			adapter.Status = "Medios desconectados"
+		default:
+			adapter.Status = "Conectado"
+		}
+
+		adapter.Physical = link.Physical
+		setMacInfo(&adapter, mac)
+		classifyAdapter(&adapter)
+		adapters = append(adapters, adapter)
+	}
+
+	return adapters, nil
+}
+
+// getGetmacAdapters es el respaldo para Windows cuando net.Interfaces no
+// devuelve nada util. Usa un lector CSV real porque los nombres de
+// adaptador pueden contener comas.
+func getGetmacAdapters(r CommandRunner) []NetworkAdapter {
+	adapters := []NetworkAdapter{}
+
+	out, err := r.Run("getmac", "/v", "/fo", "csv")
+	if err != nil {
+		return adapters
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(out)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return adapters
+	}
+
+	for i, fields := range records {
+		if i == 0 || len(fields) < 4 {
+			continue
+		}
+
+		for j := range fields {
+			fields[j] = strings.TrimSpace(fields[j])
+		}
+
+		adapter := NetworkAdapter{
+			Name:        fields[0],
+			AdapterType: fields[1],
+			MacAddress:  fields[2],
+			Status:      getmacStatus(fields[3]),
+			Source:      AdapterSourceGetmac,
+		}
+
+		mac, err := ParseMAC(adapter.MacAddress)
+		if err != nil {
+			continue
+		}
+		adapter.MacAddress = mac.String()
+		adapter.IsActive = adapter.Status == "Conectado"
+
+		setMacInfo(&adapter, mac)
+		classifyAdapter(&adapter)
+		adapters = append(adapters, adapter)
+	}
+
+	return adapters
+}
+
+// getmacStatus traduce la columna de transporte de getmac a los mismos
+// estados que la enumeracion nativa. Un adaptador con enlace muestra su
+// transporte (\Device\Tcpip_{GUID}); los demas muestran el motivo en el
+// idioma del sistema.
+func getmacStatus(transport string) string {
+	lower := strings.ToLower(transport)
+	switch {
+	case strings.HasPrefix(lower, `\device\`):
+		return "Conectado"
+	case strings.Contains(lower, "disconnected"),
+		strings.Contains(lower, "desconectad"),
+		strings.Contains(lower, "getrennt"):
+		return "Medios desconectados"
+	default:
+		return "Deshabilitado"
+	}
+}
+
+// classifyAdapter aplica las reglas de adaptadores (ver AdapterRule). Ambas
+// fuentes usan las mismas reglas y se guarda cual decidio.
+func classifyAdapter(adapter *NetworkAdapter) {
+	decision := ClassifyAdapter(*adapter)
+	adapter.IsEthernet = decision.Include
+	adapter.Rule = decision.Rule
+}
+
+// setMacInfo completa el fabricante y las marcas de MAC local o aleatoria.
+// Solo se marca como aleatoria la MAC de un adaptador inalambrico: en uno
+// cableado una MAC local es de una NIC virtio, un puente o un contenedor.
+func setMacInfo(adapter *NetworkAdapter, mac MAC) {
+	adapter.Vendor = mac.Vendor()
+	adapter.LocallyAdministered = mac.IsLocallyAdministered()
+	adapter.RandomMac = isWireless(*adapter) && mac.IsRandom()
+}
+
+// wirelessKeywords identifican un adaptador Wi-Fi por nombre o descripcion
+// cuando el sistema no informa el tipo de hardware (getmac).
+var wirelessKeywords = []string{"wi-fi", "wireless", "wlan", "802.11", "inalámbrica", "inalambrica"}
+
+func isWireless(adapter NetworkAdapter) bool {
+	if adapter.HardwareType == "wireless" {
+		return true
+	}
+	return containsAny(adapter.Name, wirelessKeywords) || containsAny(adapter.AdapterType, wirelessKeywords)
+}
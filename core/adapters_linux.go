@@ -0,0 +1,211 @@
+package core
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sysClassNet = "/sys/class/net"
+
+func loadLinkDetails(ifaces []net.Interface) map[int]linkDetails {
+	details := map[int]linkDetails{}
+	gateways := readDefaultGateways(procNetRoute)
+	for _, iface := range ifaces {
+		link := readSysfsLink(iface.Name)
+		link.Gateway = gateways[iface.Name]
+		details[iface.Index] = link
+	}
+	return details
+}
+
+const procNetRoute = "/proc/net/route"
+
+// readDefaultGateways devuelve el gateway de la ruta por defecto de cada
+// interfaz. /proc/net/route guarda las direcciones en hexadecimal
+// little-endian.
+func readDefaultGateways(path string) map[string]string {
+	gateways := map[string]string{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return gateways
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+
+		raw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || raw == 0 {
+			continue
+		}
+
+		ip := net.IPv4(byte(raw), byte(raw>>8), byte(raw>>16), byte(raw>>24))
+		if _, ok := gateways[fields[0]]; !ok {
+			gateways[fields[0]] = ip.String()
+		}
+	}
+
+	return gateways
+}
+
+func readSysfsLink(name string) linkDetails {
+	dir := filepath.Join(sysClassNet, name)
+	link := linkDetails{
+		OperState: readSysfsValue(dir, "operstate"),
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "device"))
+	link.Physical = err == nil
+
+	devtype := ""
+	for _, line := range strings.Split(readSysfsValue(dir, "uevent"), "\n") {
+		if strings.HasPrefix(line, "DEVTYPE=") {
+			devtype = strings.TrimPrefix(line, "DEVTYPE=")
+		}
+	}
+
+	arpType, _ := strconv.Atoi(readSysfsValue(dir, "type"))
+	link.HardwareType = hardwareTypeFromSysfs(arpType, devtype, dir, link.Physical)
+
+	if speed, err := strconv.ParseInt(readSysfsValue(dir, "speed"), 10, 64); err == nil && speed > 0 {
+		link.SpeedMbps = speed
+		link.Speed = formatSpeed(speed)
+	}
+	switch readSysfsValue(dir, "duplex") {
+	case "full":
+		link.Duplex = DuplexFull
+	case "half":
+		link.Duplex = DuplexHalf
+	}
+
+	if driver, err := os.Readlink(filepath.Join(dir, "device", "driver")); err == nil {
+		link.Description = filepath.Base(driver)
+	}
+
+	return link
+}
+
+// hardwareTypeFromSysfs traduce el ARPHRD de /sys/class/net/<if>/type y el
+// DEVTYPE de uevent a los tipos que usa la clasificacion.
+func hardwareTypeFromSysfs(arpType int, devtype, dir string, physical bool) string {
+	if _, err := os.Stat(filepath.Join(dir, "wireless")); err == nil || devtype == "wlan" {
+		return "wireless"
+	}
+
+	switch arpType {
+	case 772:
+		return "loopback"
+	case 512:
+		return "ppp"
+	case 65534, 768, 769, 776, 778:
+		return "tunnel"
+	}
+
+	switch devtype {
+	case "bridge":
+		return "bridge"
+	case "vlan", "bond", "vxlan", "wireguard":
+		return "virtual"
+	}
+
+	if arpType == 1 {
+		if physical {
+			return "ethernet"
+		}
+		return "virtual"
+	}
+
+	return "other"
+}
+
+func readSysfsValue(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+var (
+	resolvConfPaths = []string{"/run/systemd/resolve/resolv.conf", "/etc/resolv.conf"}
+	networkdLeases  = "/run/systemd/netif/leases"
+	nmLeasesDir     = "/var/lib/NetworkManager"
+)
+
+// loadNetworkFacts toma los DNS de resolv.conf (el de systemd-resolved si
+// existe, porque /etc/resolv.conf apunta al stub 127.0.0.53) y el DHCP del
+// lease de systemd-networkd o de NetworkManager.
+func loadNetworkFacts(r CommandRunner, adapter NetworkAdapter, facts *NetworkFacts) {
+	for _, path := range resolvConfPaths {
+		if servers := readNameservers(path); len(servers) > 0 {
+			facts.DNSServers = servers
+			break
+		}
+	}
+
+	lease := filepath.Join(networkdLeases, strconv.Itoa(adapter.Index))
+	if _, err := os.Stat(lease); err != nil {
+		matches, _ := filepath.Glob(filepath.Join(nmLeasesDir, "internal-*-"+adapter.Name+".lease"))
+		if len(matches) == 0 {
+			return
+		}
+		lease = matches[0]
+	}
+	readLease(lease, facts)
+}
+
+func readNameservers(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	servers := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" && !strings.HasPrefix(fields[1], "127.") {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
+
+// readLease interpreta el formato CLAVE=valor de los lease de systemd-networkd
+// (y del cliente interno de NetworkManager). El archivo se reescribe al
+// renovar, asi que su fecha es la de obtencion.
+func readLease(path string, facts *NetworkFacts) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	facts.DHCPEnabled = boolPtr(true)
+	obtained := info.ModTime()
+	facts.LeaseObtained = formatLeaseTime(obtained.Unix())
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "SERVER_ADDRESS":
+			facts.DHCPServer = value
+		case "LIFETIME":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				facts.LeaseExpires = formatLeaseTime(obtained.Add(time.Duration(secs) * time.Second).Unix())
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadNetworkFactsLease(t *testing.T) {
+	dir := t.TempDir()
+	prevResolv, prevNetworkd, prevNM := resolvConfPaths, networkdLeases, nmLeasesDir
+	t.Cleanup(func() { resolvConfPaths, networkdLeases, nmLeasesDir = prevResolv, prevNetworkd, prevNM })
+	resolvConfPaths = []string{filepath.Join(dir, "resolv.conf")}
+	networkdLeases = filepath.Join(dir, "leases")
+	nmLeasesDir = dir
+
+	if err := os.MkdirAll(networkdLeases, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lease := "ADDRESS=10.1.1.10\nSERVER_ADDRESS=10.1.1.1\nLIFETIME=86400\n"
+	if err := os.WriteFile(filepath.Join(networkdLeases, "2"), []byte(lease), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var facts NetworkFacts
+	loadNetworkFacts(ExecRunner{}, NetworkAdapter{Name: "eth0", Index: 2}, &facts)
+	if facts.DHCPEnabled == nil || !*facts.DHCPEnabled || facts.DHCPServer != "10.1.1.1" || facts.LeaseExpires == "" {
+		t.Errorf("con lease: %+v", facts)
+	}
+
+	// Sin lease puede ser IP fija o un cliente DHCP que no deja archivo:
+	// no se sabe.
+	facts = NetworkFacts{}
+	loadNetworkFacts(ExecRunner{}, NetworkAdapter{Name: "eth1", Index: 3}, &facts)
+	if facts.DHCPEnabled != nil || facts.DHCPServer != "" {
+		t.Errorf("sin lease: %+v, se esperaba el DHCP desconocido", facts)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package core
+
+import "net"
+
+func loadLinkDetails(ifaces []net.Interface) map[int]linkDetails {
+	return map[int]linkDetails{}
+}
+
+func loadNetworkFacts(r CommandRunner, adapter NetworkAdapter, facts *NetworkFacts) {}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Valores IF_TYPE de ipifcons.h; syscall no los exporta.
+const (
+	ifTypeEthernet  = 6
+	ifTypePPP       = 23
+	ifTypeLoopback  = 24
+	ifTypeIEEE80211 = 71
+)
+
+// Flags de GetAdaptersAddresses (iptypes.h) que x/sys no define.
+const (
+	gaaFlagSkipAnycast   = 0x0002
+	gaaFlagSkipMulticast = 0x0004
+)
+
+func loadLinkDetails(ifaces []net.Interface) map[int]linkDetails {
+	details := map[int]linkDetails{}
+
+	forEachAdapterInfo(func(ai *syscall.IpAdapterInfo) {
+		link := linkDetails{
+			Description:  cString(ai.Description[:]),
+			HardwareType: hardwareTypeFromIfType(ai.Type),
+			Physical:     ai.Type == ifTypeEthernet,
+		}
+		if gw := cString(ai.GatewayList.IpAddress.String[:]); gw != "" && gw != "0.0.0.0" {
+			link.Gateway = gw
+		}
+		details[int(ai.Index)] = link
+	})
+
+	forEachAdapterAddresses(func(aa *windows.IpAdapterAddresses) {
+		link, ok := details[int(aa.IfIndex)]
+		if !ok || aa.TransmitLinkSpeed == 0 || aa.TransmitLinkSpeed == ^uint64(0) {
+			return
+		}
+		link.SpeedMbps = int64(aa.TransmitLinkSpeed / 1000000)
+		link.Speed = formatSpeed(link.SpeedMbps)
+		details[int(aa.IfIndex)] = link
+	})
+
+	return details
+}
+
+// forEachAdapterInfo recorre la lista de GetAdaptersInfo, agrandando el
+// buffer si hace falta.
+func forEachAdapterInfo(fn func(ai *syscall.IpAdapterInfo)) {
+	size := uint32(16 * 1024)
+	for attempt := 0; attempt < 3; attempt++ {
+		buf := make([]byte, size)
+		info := (*syscall.IpAdapterInfo)(unsafe.Pointer(&buf[0]))
+
+		err := syscall.GetAdaptersInfo(info, &size)
+		if err == syscall.ERROR_BUFFER_OVERFLOW {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		for ai := info; ai != nil; ai = ai.Next {
+			fn(ai)
+		}
+		return
+	}
+}
+
+// forEachAdapterAddresses recorre GetAdaptersAddresses, que agrega lo que
+// GetAdaptersInfo no tiene: servidores DNS y velocidad del enlace.
+func forEachAdapterAddresses(fn func(aa *windows.IpAdapterAddresses)) {
+	size := uint32(16 * 1024)
+	for attempt := 0; attempt < 3; attempt++ {
+		buf := make([]byte, size)
+		info := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0]))
+
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, gaaFlagSkipAnycast|gaaFlagSkipMulticast, 0, info, &size)
+		if err == windows.ERROR_BUFFER_OVERFLOW {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		for aa := info; aa != nil; aa = aa.Next {
+			fn(aa)
+		}
+		return
+	}
+}
+
+func hardwareTypeFromIfType(ifType uint32) string {
+	switch ifType {
+	case ifTypeEthernet:
+		return "ethernet"
+	case ifTypeIEEE80211:
+		return "wireless"
+	case ifTypeLoopback:
+		return "loopback"
+	case ifTypePPP:
+		return "ppp"
+	}
+	return "other"
+}
+
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+// loadNetworkFacts completa DNS y DHCP con las APIs de IP Helper. El duplex
+// no esta en ninguna de las dos y se consulta a Get-NetAdapter.
+func loadNetworkFacts(r CommandRunner, adapter NetworkAdapter, facts *NetworkFacts) {
+	forEachAdapterInfo(func(ai *syscall.IpAdapterInfo) {
+		if int(ai.Index) != adapter.Index {
+			return
+		}
+		facts.DHCPEnabled = boolPtr(ai.DhcpEnabled != 0)
+		if ai.DhcpEnabled == 0 {
+			return
+		}
+		if server := cString(ai.DhcpServer.IpAddress.String[:]); server != "0.0.0.0" {
+			facts.DHCPServer = server
+		}
+		facts.LeaseObtained = formatLeaseTime(ai.LeaseObtained)
+		facts.LeaseExpires = formatLeaseTime(ai.LeaseExpires)
+	})
+
+	forEachAdapterAddresses(func(aa *windows.IpAdapterAddresses) {
+		if int(aa.IfIndex) != adapter.Index {
+			return
+		}
+		for dns := aa.FirstDnsServerAddress; dns != nil; dns = dns.Next {
+			if ip := dns.Address.IP(); ip != nil && !ip.IsLinkLocalUnicast() {
+				facts.DNSServers = append(facts.DNSServers, ip.String())
+			}
+		}
+	})
+
+	// Si IP Helper no devolvio nada del adaptador se consulta CIM.
+	if len(facts.DNSServers) == 0 && (facts.DHCPEnabled == nil || !*facts.DHCPEnabled) && useCIM() {
+		if c, ok := cimAdapterConfiguration(r, adapter.Index); ok {
+			facts.DNSServers = c.DNSServerSearchOrder
+			facts.DHCPEnabled = boolPtr(c.DHCPEnabled)
+			facts.DHCPServer = c.DHCPServer
+			if facts.Gateway == "" && len(c.DefaultIPGateway) > 0 {
+				facts.Gateway = c.DefaultIPGateway[0]
+			}
+		}
+	}
+
+	if facts.Duplex == "" && adapter.Index > 0 {
+		out, err := runWithTimeout(r, cimTimeout, "powershell", "-NoProfile", "-Command",
+			fmt.Sprintf("(Get-NetAdapter -InterfaceIndex %d).FullDuplex", adapter.Index))
+		if err == nil {
+			facts.Duplex = parseFullDuplex(string(out))
+		}
+	}
+}
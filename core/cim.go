@@ -0,0 +1,173 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backends de los colectores de Windows. En auto se consulta CIM por
+// PowerShell y, si falla, wmic, que ya no viene en las ultimas versiones de
+// Windows 11.
+const (
+	BackendAuto = "auto"
+	BackendCIM  = "cim"
+	BackendWMIC = "wmic"
+)
+
+// cimTimeout es lo que se espera a Get-CimInstance antes de pasar a wmic.
+const cimTimeout = 20 * time.Second
+
+var (
+	backendMu        sync.RWMutex
+	collectorBackend = BackendAuto
+)
+
+// SetCollectorBackend elige el backend de los colectores: auto, cim o wmic.
+func SetCollectorBackend(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = BackendAuto
+	}
+
+	switch name {
+	case BackendAuto, BackendCIM, BackendWMIC:
+	default:
+		return fmt.Errorf("backend de colectores invalido: %q (use auto, cim o wmic)", name)
+	}
+
+	backendMu.Lock()
+	collectorBackend = name
+	backendMu.Unlock()
+	return nil
+}
+
+func currentBackend() string {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return collectorBackend
+}
+
+func useCIM() bool {
+	return currentBackend() != BackendWMIC
+}
+
+func useWMIC() bool {
+	return currentBackend() != BackendCIM
+}
+
+// Clases CIM que se consultan, con solo las propiedades que se usan.
+type cimComputerSystem struct {
+	Domain       string
+	PartOfDomain bool
+	Manufacturer string
+	Model        string
+}
+
+type cimBIOS struct {
+	SerialNumber string
+	Version      string
+}
+
+type cimProcessor struct {
+	Name string
+}
+
+type cimNetworkLoginProfile struct {
+	Name string
+}
+
+type cimNetworkAdapterConfiguration struct {
+	InterfaceIndex       int
+	DHCPEnabled          bool
+	DHCPServer           string
+	DefaultIPGateway     []string
+	DNSServerSearchOrder []string
+}
+
+// queryCIM ejecuta Get-CimInstance y decodifica el JSON en out, que debe
+// ser un puntero a slice. ConvertTo-Json devuelve un objeto cuando hay una
+// sola instancia y un arreglo cuando hay varias; sin instancias no imprime
+// nada. Si no responde en cimTimeout devuelve error y se usa wmic.
+func queryCIM(r CommandRunner, class string, out interface{}, properties ...string) error {
+	command := fmt.Sprintf("Get-CimInstance -ClassName %s | Select-Object %s | ConvertTo-Json -Compress",
+		class, strings.Join(properties, ","))
+
+	data, err := runWithTimeout(r, cimTimeout, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
+	if err != nil {
+		return fmt.Errorf("error consultando %s: %v", class, err)
+	}
+
+	text := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	switch {
+	case text == "":
+		text = "[]"
+	case strings.HasPrefix(text, "{"):
+		text = "[" + text + "]"
+	}
+
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		return fmt.Errorf("respuesta de %s invalida: %v", class, err)
+	}
+	return nil
+}
+
+func cimProcessorName(r CommandRunner) (string, bool) {
+	var processors []cimProcessor
+	if err := queryCIM(r, "Win32_Processor", &processors, "Name"); err != nil || len(processors) == 0 {
+		return "", false
+	}
+
+	name := strings.TrimSpace(processors[0].Name)
+	return name, name != ""
+}
+
+func cimBIOSInfo(r CommandRunner) (cimBIOS, bool) {
+	var bios []cimBIOS
+	if err := queryCIM(r, "Win32_BIOS", &bios, "SerialNumber", "Version"); err != nil || len(bios) == 0 {
+		return cimBIOS{}, false
+	}
+	return bios[0], true
+}
+
+func cimComputerSystemInfo(r CommandRunner) (cimComputerSystem, bool) {
+	var systems []cimComputerSystem
+	err := queryCIM(r, "Win32_ComputerSystem", &systems, "Domain", "PartOfDomain", "Manufacturer", "Model")
+	if err != nil || len(systems) == 0 {
+		return cimComputerSystem{}, false
+	}
+	return systems[0], true
+}
+
+func cimLoginProfiles(r CommandRunner) ([]string, bool) {
+	var profiles []cimNetworkLoginProfile
+	if err := queryCIM(r, "Win32_NetworkLoginProfile", &profiles, "Name"); err != nil {
+		return nil, false
+	}
+
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		if name := strings.TrimSpace(p.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, true
+}
+
+func cimAdapterConfiguration(r CommandRunner, index int) (cimNetworkAdapterConfiguration, bool) {
+	var configs []cimNetworkAdapterConfiguration
+	err := queryCIM(r, "Win32_NetworkAdapterConfiguration", &configs,
+		"InterfaceIndex", "DHCPEnabled", "DHCPServer", "DefaultIPGateway", "DNSServerSearchOrder")
+	if err != nil {
+		return cimNetworkAdapterConfiguration{}, false
+	}
+
+	for _, c := range configs {
+		if c.InterfaceIndex == index {
+			return c, true
+		}
+	}
+	return cimNetworkAdapterConfiguration{}, false
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile decodifica en v un archivo JSON o YAML segun la extension.
+// Los errores de lectura vuelven sin envolver; los de formato nombran el
+// archivo.
+func loadConfigFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, v)
+	default:
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("%s invalido: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// consoleBuffer es cuantas lineas puede adelantar el lector sin que nadie
+// las pida, por ejemplo cuando la entrada viene de un pipe.
+const consoleBuffer = 64
+
+var (
+	consoleOnce  sync.Once
+	consoleLines chan string
+
+	consoleMu    sync.Mutex
+	consoleStale bool
+)
+
+// ReadConsoleLine lee una linea de la consola. Con timeout 0 espera sin
+// limite. Devuelve false si vence el tiempo o se cerro la entrada.
+//
+// Todas las lecturas pasan por un unico lector que deja las lineas en un
+// canal con buffer. Si una pregunta vence, lo que se escriba despues es la
+// respuesta tardia a esa pregunta y se descarta al empezar la siguiente.
+func ReadConsoleLine(timeout time.Duration) (string, bool) {
+	consoleOnce.Do(startConsoleReader)
+	drainStaleConsole()
+
+	if timeout <= 0 {
+		line, ok := <-consoleLines
+		return line, ok
+	}
+
+	select {
+	case line, ok := <-consoleLines:
+		return line, ok
+	case <-time.After(timeout):
+		consoleMu.Lock()
+		consoleStale = true
+		consoleMu.Unlock()
+		return "", false
+	}
+}
+
+// drainStaleConsole descarta las lineas pendientes si la pregunta anterior
+// vencio sin respuesta.
+func drainStaleConsole() {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+
+	if !consoleStale {
+		return
+	}
+	consoleStale = false
+
+	for {
+		select {
+		case _, ok := <-consoleLines:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func startConsoleReader() {
+	consoleLines = readConsoleLines(os.Stdin)
+}
+
+// readConsoleLines lee r en segundo plano y entrega cada linea sin espacios
+// en el canal, que se cierra al terminar la entrada.
+func readConsoleLines(r io.Reader) chan string {
+	lines := make(chan string, consoleBuffer)
+
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" || err == nil {
+				lines <- strings.TrimSpace(line)
+			}
+			if err != nil {
+				close(lines)
+				return
+			}
+		}
+	}()
+
+	return lines
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReadConsoleLineDropsLateAnswers(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	consoleOnce.Do(func() {})
+	consoleLines = readConsoleLines(r)
+
+	go io.WriteString(w, "1\n2\n")
+	for _, want := range []string{"1", "2"} {
+		if line, ok := ReadConsoleLine(time.Second); !ok || line != want {
+			t.Fatalf("ReadConsoleLine = %q, %v; se esperaba %q", line, ok, want)
+		}
+	}
+
+	if _, ok := ReadConsoleLine(20 * time.Millisecond); ok {
+		t.Fatal("sin entrada la lectura debe vencer")
+	}
+	go io.WriteString(w, "tarde\n")
+	for len(consoleLines) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	go io.WriteString(w, "nueva\n")
+	if line, ok := ReadConsoleLine(time.Second); !ok || line != "nueva" {
+		t.Fatalf("ReadConsoleLine = %q, %v; la respuesta tardia debio descartarse", line, ok)
+	}
+}
@@ -1,7 +1,6 @@
 package core
 
 import (
-	"os/exec"
 	"strings"
 )
 
@@ -11,15 +10,30 @@ type DomainInfo struct {
 	EsMecLocal    bool
 }
 
-func GetDomainInfo() DomainInfo {
+// windowsDomainInfo consulta Win32_ComputerSystem por CIM y, si no
+// responde, wmic.
+func windowsDomainInfo(r CommandRunner) DomainInfo {
 	info := DomainInfo{
 		EnDominio:     false,
 		NombreDominio: "",
 		EsMecLocal:    false,
 	}
 
-	cmd := exec.Command("wmic", "computersystem", "get", "domain")
-	out, err := cmd.Output()
+	if useCIM() {
+		if cs, ok := cimComputerSystemInfo(r); ok {
+			if cs.PartOfDomain && cs.Domain != "" {
+				info.EnDominio = true
+				info.NombreDominio = strings.TrimSpace(cs.Domain)
+				info.EsMecLocal = strings.EqualFold(info.NombreDominio, "mec.local")
+			}
+			return info
+		}
+	}
+	if !useWMIC() {
+		return info
+	}
+
+	out, err := r.Run("wmic", "computersystem", "get", "domain")
 	if err != nil {
 		return info
 	}
@@ -54,45 +68,27 @@ func GetDomainInfo() DomainInfo {
 	return info
 }
 
-func GetDomainInfoAlternative() DomainInfo {
+// windowsDomainInfoAlternative toma el dominio de systeminfo.
+func windowsDomainInfoAlternative(r CommandRunner) DomainInfo {
 	info := DomainInfo{
 		EnDominio:     false,
 		NombreDominio: "",
 		EsMecLocal:    false,
 	}
 
-	cmd := exec.Command("systeminfo")
-	out, err := cmd.Output()
+	row, err := runSystemInfoCSV(r)
 	if err != nil {
 		return info
 	}
 
-	lines := strings.Split(string(out), "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(line, "Dominio:") || strings.HasPrefix(line, "Domain:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				dominio := strings.TrimSpace(parts[1])
-				dominioLower := strings.ToLower(dominio)
-
-				if dominioLower == "workgroup" {
-					return info
-				}
-
-				info.EnDominio = true
-				info.NombreDominio = dominio
-
-				if dominioLower == "mec.local" {
-					info.EsMecLocal = true
-				}
-
-				return info
-			}
-		}
+	dominio, ok := row.field(siColDomain)
+	if !ok || dominio == "" || strings.EqualFold(dominio, "workgroup") {
+		return info
 	}
 
+	info.EnDominio = true
+	info.NombreDominio = dominio
+	info.EsMecLocal = strings.EqualFold(dominio, "mec.local")
+
 	return info
 }
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExpectedFixtureFile guarda, dentro de cada carpeta del corpus, lo que los
+// colectores deben devolver al reproducir esas salidas.
+const ExpectedFixtureFile = "expected.json"
+
+// FixtureSnapshot es el resultado de todos los colectores que dependen de
+// comandos externos.
+type FixtureSnapshot struct {
+	SystemInfo        SystemInfo       `json:"system_info"`
+	SystemInfoReport  SystemInfoReport `json:"system_info_report"`
+	SerialNumber      string           `json:"serial_number"`
+	BIOSVersion       string           `json:"bios_version"`
+	Domain            DomainInfo       `json:"domain"`
+	DomainAlternative DomainInfo       `json:"domain_alternative"`
+	GetmacAdapters    []NetworkAdapter `json:"getmac_adapters"`
+}
+
+// CollectWithRunner ejecuta los colectores de Windows con el runner indicado.
+// Se usan los de Windows en cualquier sistema porque el corpus son salidas
+// de comandos de Windows.
+func CollectWithRunner(r CommandRunner) FixtureSnapshot {
+	snapshot := FixtureSnapshot{
+		Domain:            windowsDomainInfo(r),
+		DomainAlternative: windowsDomainInfoAlternative(r),
+		GetmacAdapters:    getGetmacAdapters(r),
+	}
+	snapshot.SystemInfo, snapshot.SystemInfoReport = windowsSystemInfoReport(r)
+	snapshot.SerialNumber, snapshot.BIOSVersion = windowsBIOSInfo(r)
+
+	// La arquitectura sale de runtime.GOARCH y no de los comandos.
+	snapshot.SystemInfo.Architecture = ""
+
+	return snapshot
+}
+
+// RecordFixtures ejecuta los colectores reales, guarda cada salida en dir y
+// escribe expected.json con lo que se obtuvo, para revisarlo a mano.
+func RecordFixtures(dir string) (FixtureSnapshot, error) {
+	snapshot := CollectWithRunner(&RecordingRunner{Runner: ExecRunner{}, Dir: dir})
+
+	if err := writeExpectedFixture(dir, snapshot); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+// writeExpectedFixture guarda snapshot como expected.json de la carpeta.
+func writeExpectedFixture(dir string, snapshot FixtureSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creando %s: %w", dir, err)
+	}
+	return os.WriteFile(filepath.Join(dir, ExpectedFixtureFile), append(data, '\n'), 0644)
+}
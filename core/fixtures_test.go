@@ -0,0 +1,128 @@
+package core
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const fixtureRoot = "testdata/commands"
+
+var updateFixtures = flag.Bool("update", false, "reescribir expected.json con el resultado actual")
+
+// TestFixtureCorpus reproduce cada carpeta de testdata/commands con
+// FixtureRunner y compara el resultado de los parsers con su expected.json.
+// Con -update reescribe expected.json; revise el diff antes de confirmarlo.
+func TestFixtureCorpus(t *testing.T) {
+	entries, err := os.ReadDir(fixtureRoot)
+	if err != nil {
+		t.Fatalf("error leyendo corpus: %v", err)
+	}
+
+	found := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(fixtureRoot, entry.Name())
+		found++
+
+		t.Run(entry.Name(), func(t *testing.T) {
+			actual := CollectWithRunner(NewFixtureRunner(dir))
+
+			if *updateFixtures {
+				if err := writeExpectedFixture(dir, actual); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, ExpectedFixtureFile))
+			if err != nil {
+				t.Fatalf("falta %s: %v", ExpectedFixtureFile, err)
+			}
+
+			var expected FixtureSnapshot
+			if err := json.Unmarshal(data, &expected); err != nil {
+				t.Fatalf("%s invalido: %v", ExpectedFixtureFile, err)
+			}
+
+			expectedJSON, _ := json.MarshalIndent(expected, "", "  ")
+			actualJSON, _ := json.MarshalIndent(actual, "", "  ")
+			if string(expectedJSON) != string(actualJSON) {
+				t.Errorf("el resultado no coincide con %s\nesperado:\n%s\nobtenido:\n%s",
+					ExpectedFixtureFile, expectedJSON, actualJSON)
+			}
+		})
+	}
+
+	if found == 0 {
+		t.Fatalf("no hay corpus en %s", fixtureRoot)
+	}
+}
+
+func TestFixtureRunnerMissingCommand(t *testing.T) {
+	r := NewFixtureRunner(t.TempDir())
+	if _, err := r.Run("wmic", "bios", "get", "version"); err == nil {
+		t.Fatal("un comando sin salida grabada debe fallar")
+	}
+}
+
+func TestRecordingRunnerRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	source := NewFixtureRunner(filepath.Join(fixtureRoot, "es-windows10"))
+	recorder := &RecordingRunner{Runner: source, Dir: dir}
+
+	want, err := recorder.Run("wmic", "bios", "get", "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewFixtureRunner(dir).Run("wmic", "bios", "get", "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("salida grabada %q, se esperaba %q", got, want)
+	}
+
+	if _, err := recorder.Run("wmic", "no", "existe"); err == nil {
+		t.Fatal("se esperaba error del comando sin salida")
+	}
+	if _, err := NewFixtureRunner(dir).Run("wmic", "no", "existe"); err == nil {
+		t.Fatal("el error grabado debe reproducirse")
+	}
+}
+
+func TestSystemInfoFallsBackToCIM(t *testing.T) {
+	source := filepath.Join(fixtureRoot, "es-windows11-24h2")
+	dir := t.TempDir()
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(source, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	systeminfo := FixtureKey("systeminfo", "/FO", "CSV")
+	os.Remove(filepath.Join(dir, systeminfo+".txt"))
+	if err := os.WriteFile(filepath.Join(dir, systeminfo+".err"), []byte("systeminfo no respondio\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, report := windowsSystemInfoReport(NewFixtureRunner(dir))
+	if info.Manufacturer != "LENOVO" || info.Model != "11JN003QLS" {
+		t.Errorf("fabricante/modelo = %q/%q, se esperaba LENOVO/11JN003QLS", info.Manufacturer, info.Model)
+	}
+	if report.ResolvedBy["model"] != BackendCIM || report.ResolvedBy["manufacturer"] != BackendCIM {
+		t.Errorf("ResolvedBy = %v, se esperaba cim", report.ResolvedBy)
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// LocationMapping asocia una subred con su ubicacion. Los campos vacios no
+// se sugieren ni se comparan (por ejemplo una VLAN por piso sin oficina).
+type LocationMapping struct {
+	CIDR     string `json:"cidr" yaml:"cidr"`
+	Edificio string `json:"edificio,omitempty" yaml:"edificio,omitempty"`
+	Piso     string `json:"piso,omitempty" yaml:"piso,omitempty"`
+	Oficina  string `json:"oficina,omitempty" yaml:"oficina,omitempty"`
+
+	network *net.IPNet
+}
+
+type locationMapFile struct {
+	Locations []LocationMapping `json:"locations" yaml:"locations"`
+}
+
+var (
+	locationMapMu sync.RWMutex
+	locationMap   []LocationMapping
+)
+
+// LoadLocationMap reemplaza el mapa de ubicaciones por el de un archivo JSON
+// o YAML (segun la extension) con la lista en "locations".
+func LoadLocationMap(path string) error {
+	var file locationMapFile
+	if err := loadConfigFile(path, &file); err != nil {
+		return fmt.Errorf("error leyendo mapa de ubicaciones: %w", err)
+	}
+
+	if len(file.Locations) == 0 {
+		return fmt.Errorf("el archivo %s no tiene ubicaciones", path)
+	}
+	for i := range file.Locations {
+		m := &file.Locations[i]
+		_, network, err := net.ParseCIDR(m.CIDR)
+		if err != nil {
+			return fmt.Errorf("ubicacion %d: CIDR invalido %q", i+1, m.CIDR)
+		}
+		if m.Edificio == "" && m.Piso == "" && m.Oficina == "" {
+			return fmt.Errorf("ubicacion %d (%s): falta edificio, piso u oficina", i+1, m.CIDR)
+		}
+		m.network = network
+		m.CIDR = network.String()
+	}
+
+	locationMapMu.Lock()
+	locationMap = file.Locations
+	locationMapMu.Unlock()
+	return nil
+}
+
+// HasLocationMap indica si se cargo un mapa de ubicaciones.
+func HasLocationMap() bool {
+	locationMapMu.RLock()
+	defer locationMapMu.RUnlock()
+	return len(locationMap) > 0
+}
+
+// LookupLocation devuelve la ubicacion de la subred mas especifica que
+// contiene ip.
+func LookupLocation(ip string) (LocationMapping, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return LocationMapping{}, false
+	}
+
+	locationMapMu.RLock()
+	defer locationMapMu.RUnlock()
+
+	best, bestBits := -1, -1
+	for i, m := range locationMap {
+		if !m.network.Contains(parsed) {
+			continue
+		}
+		if ones, _ := m.network.Mask.Size(); ones > bestBits {
+			best, bestBits = i, ones
+		}
+	}
+
+	if best < 0 {
+		return LocationMapping{}, false
+	}
+	return locationMap[best], true
+}
+
+// Conflicts devuelve los campos en los que la ubicacion configurada
+// contradice a la subred. Una ubicacion vacia no contradice nada.
+func (m LocationMapping) Conflicts(config LocationConfig) []string {
+	conflicts := []string{}
+	if m.Piso != "" && config.Piso != "" && !strings.EqualFold(m.Piso, config.Piso) {
+		conflicts = append(conflicts, fmt.Sprintf("piso %s (subred: %s)", config.Piso, m.Piso))
+	}
+	if m.Oficina != "" && config.Oficina != "" && !strings.EqualFold(m.Oficina, config.Oficina) {
+		conflicts = append(conflicts, fmt.Sprintf("oficina %s (subred: %s)", config.Oficina, m.Oficina))
+	}
+	return conflicts
+}
+
+// Apply completa config con los datos de la subred, pisando los que esta
+// define.
+func (m LocationMapping) Apply(config LocationConfig) LocationConfig {
+	if m.Piso != "" {
+		config.Piso = m.Piso
+	}
+	if m.Oficina != "" {
+		config.Oficina = m.Oficina
+	}
+	return config
+}
+
+// Fill completa solo los campos vacios de config con los de la subred; lo
+// configurado se respeta.
+func (m LocationMapping) Fill(config LocationConfig) LocationConfig {
+	if config.Piso == "" {
+		config.Piso = m.Piso
+	}
+	if config.Oficina == "" {
+		config.Oficina = m.Oficina
+	}
+	return config
+}
+
+func (m LocationMapping) String() string {
+	parts := []string{}
+	if m.Edificio != "" {
+		parts = append(parts, m.Edificio)
+	}
+	if m.Piso != "" {
+		parts = append(parts, "Piso "+m.Piso)
+	}
+	if m.Oficina != "" {
+		parts = append(parts, m.Oficina)
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, " - "), m.CIDR)
+}
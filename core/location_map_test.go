@@ -0,0 +1,35 @@
+package core
+
+import "testing"
+
+func TestLocationMappingFill(t *testing.T) {
+	mapping := LocationMapping{CIDR: "10.20.5.0/24", Edificio: "Sede Central", Piso: "3", Oficina: "Compras"}
+
+	tests := []struct {
+		name      string
+		config    LocationConfig
+		fill      LocationConfig
+		apply     LocationConfig
+		conflicts int
+	}{
+		{"sin configurar", LocationConfig{}, LocationConfig{Piso: "3", Oficina: "Compras"}, LocationConfig{Piso: "3", Oficina: "Compras"}, 0},
+		{"falta la oficina", LocationConfig{Piso: "3"}, LocationConfig{Piso: "3", Oficina: "Compras"}, LocationConfig{Piso: "3", Oficina: "Compras"}, 0},
+		{"difiere en mayusculas", LocationConfig{Piso: "3", Oficina: "COMPRAS"}, LocationConfig{Piso: "3", Oficina: "COMPRAS"}, LocationConfig{Piso: "3", Oficina: "Compras"}, 0},
+		{"contradice el piso", LocationConfig{Piso: "2"}, LocationConfig{Piso: "2", Oficina: "Compras"}, LocationConfig{Piso: "3", Oficina: "Compras"}, 1},
+		{"contradice todo", LocationConfig{Piso: "1", Oficina: "Tesoreria"}, LocationConfig{Piso: "1", Oficina: "Tesoreria"}, LocationConfig{Piso: "3", Oficina: "Compras"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapping.Fill(tt.config); got != tt.fill {
+				t.Errorf("Fill() = %+v, se esperaba %+v", got, tt.fill)
+			}
+			if got := mapping.Apply(tt.config); got != tt.apply {
+				t.Errorf("Apply() = %+v, se esperaba %+v", got, tt.apply)
+			}
+			if got := mapping.Conflicts(tt.config); len(got) != tt.conflicts {
+				t.Errorf("Conflicts() = %v, se esperaban %d", got, tt.conflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MAC es una direccion de hardware de 6 bytes. Se guarda siempre en la forma
+// canonica de String (AA-BB-CC-DD-EE-FF), la misma que imprime getmac, para
+// que las busquedas por MAC coincidan sin importar de donde vino.
+type MAC [6]byte
+
+// ParseMAC acepta las notaciones habituales: AA-BB-CC-DD-EE-FF,
+// aa:bb:cc:dd:ee:ff, aabb.ccdd.eeff (Cisco) y AABBCCDDEEFF.
+func ParseMAC(s string) (MAC, error) {
+	var mac MAC
+
+	raw := strings.TrimSpace(s)
+	hex := ""
+
+	switch {
+	case len(raw) == 17 && (strings.Count(raw, "-") == 5 || strings.Count(raw, ":") == 5):
+		sep := raw[2:3]
+		for i, part := range strings.Split(raw, sep) {
+			if len(part) != 2 || (i > 0 && raw[i*3-1:i*3] != sep) {
+				return mac, fmt.Errorf("MAC invalida: %s", s)
+			}
+			hex += part
+		}
+	case len(raw) == 14 && strings.Count(raw, ".") == 2:
+		for _, part := range strings.Split(raw, ".") {
+			if len(part) != 4 {
+				return mac, fmt.Errorf("MAC invalida: %s", s)
+			}
+			hex += part
+		}
+	case len(raw) == 12:
+		hex = raw
+	default:
+		return mac, fmt.Errorf("MAC invalida: %s", s)
+	}
+
+	for i := 0; i < 6; i++ {
+		hi, ok1 := hexValue(hex[i*2])
+		lo, ok2 := hexValue(hex[i*2+1])
+		if !ok1 || !ok2 {
+			return mac, fmt.Errorf("MAC invalida: %s", s)
+		}
+		mac[i] = hi<<4 | lo
+	}
+
+	return mac, nil
+}
+
+// MACFromHardwareAddr convierte lo que devuelve net.Interfaces.
+func MACFromHardwareAddr(hw net.HardwareAddr) (MAC, bool) {
+	var mac MAC
+	if len(hw) != 6 {
+		return mac, false
+	}
+	copy(mac[:], hw)
+	return mac, true
+}
+
+func (m MAC) String() string {
+	return fmt.Sprintf("%02X-%02X-%02X-%02X-%02X-%02X", m[0], m[1], m[2], m[3], m[4], m[5])
+}
+
+// OUI devuelve los 3 primeros bytes en hexadecimal (AABBCC).
+func (m MAC) OUI() string {
+	return fmt.Sprintf("%02X%02X%02X", m[0], m[1], m[2])
+}
+
+func (m MAC) IsZero() bool {
+	return m == MAC{}
+}
+
+func (m MAC) IsMulticast() bool {
+	return m[0]&0x01 != 0
+}
+
+// IsLocallyAdministered indica que la MAC no fue asignada por el fabricante
+// (bit U/L del primer byte): adaptadores virtuales o MAC privadas.
+func (m MAC) IsLocallyAdministered() bool {
+	return m[0]&0x02 != 0
+}
+
+// IsRandom indica una MAC que puede ser privada o aleatoria como las que
+// usan Windows, Android e iOS en Wi-Fi: administrada localmente, unicast y
+// sin un prefijo conocido de virtualizacion. La MAC sola no lo distingue de
+// una NIC virtual; ver setMacInfo.
+func (m MAC) IsRandom() bool {
+	if !m.IsLocallyAdministered() || m.IsMulticast() {
+		return false
+	}
+	return !m.hasVirtualLocalPrefix()
+}
+
+// Vendor devuelve el fabricante segun la tabla OUI, o "" si no se conoce.
+// Las MAC administradas localmente no tienen fabricante.
+func (m MAC) Vendor() string {
+	if m.IsLocallyAdministered() {
+		return ""
+	}
+	return LookupOUI(m.OUI())
+}
+
+// NormalizeMac devuelve la MAC en forma canonica. Si no se puede
+// interpretar devuelve el texto original sin espacios.
+func NormalizeMac(s string) string {
+	mac, err := ParseMAC(s)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+	return mac.String()
+}
+
+// virtualLocalPrefixes son prefijos administrados localmente que usan por
+// defecto algunas herramientas; no son MAC aleatorias.
+var virtualLocalPrefixes = []string{
+	"0A0027", // VirtualBox host-only
+	"525400", // QEMU/KVM
+	"0242",   // Docker
+}
+
+func (m MAC) hasVirtualLocalPrefix() bool {
+	hex := strings.ReplaceAll(m.String(), "-", "")
+	for _, prefix := range virtualLocalPrefixes {
+		if strings.HasPrefix(hex, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,120 @@
+package core
+
+import "testing"
+
+func TestParseMAC(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "00-1A-2B-3C-4D-5E", want: "00-1A-2B-3C-4D-5E"},
+		{in: "00:1a:2b:3c:4d:5e", want: "00-1A-2B-3C-4D-5E"},
+		{in: "001a.2b3c.4d5e", want: "00-1A-2B-3C-4D-5E"},
+		{in: "001A2B3C4D5E", want: "00-1A-2B-3C-4D-5E"},
+		{in: "  00-1a-2b-3c-4d-5e\r\n", want: "00-1A-2B-3C-4D-5E"},
+		{in: "00-1A:2B-3C-4D-5E", wantErr: true},
+		{in: "00-1A-2B-3C-4D", wantErr: true},
+		{in: "0-01A-2B-3C-4D-5E", wantErr: true},
+		{in: "00-1A-2B-3C-4D-5G", wantErr: true},
+		{in: "001a.2b3c4.d5e", wantErr: true},
+		{in: "N/A", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			mac, err := ParseMAC(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseMAC(%q) = %s, se esperaba error", tt.in, mac)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMAC(%q): %v", tt.in, err)
+			}
+			if mac.String() != tt.want {
+				t.Errorf("ParseMAC(%q) = %s, se esperaba %s", tt.in, mac, tt.want)
+			}
+		})
+	}
+}
+
+func TestMACClassification(t *testing.T) {
+	tests := []struct {
+		mac    string
+		local  bool
+		random bool
+		vendor string
+	}{
+		{mac: "00-02-B3-11-22-33", vendor: "Intel Corporate"},
+		{mac: "00-0C-29-11-22-33", vendor: "VMware, Inc."},
+		{mac: "A8-5E-45-0C-77-19"},
+		{mac: "DA-A1-19-6B-2F-04", local: true, random: true},
+		{mac: "0A-00-27-00-00-0C", local: true},
+		{mac: "52-54-00-12-34-56", local: true},
+		{mac: "02-42-AC-11-00-02", local: true},
+		{mac: "03-00-00-00-00-01", local: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mac, func(t *testing.T) {
+			mac, err := ParseMAC(tt.mac)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := mac.IsLocallyAdministered(); got != tt.local {
+				t.Errorf("IsLocallyAdministered() = %v, se esperaba %v", got, tt.local)
+			}
+			if got := mac.IsRandom(); got != tt.random {
+				t.Errorf("IsRandom() = %v, se esperaba %v", got, tt.random)
+			}
+			if got := mac.Vendor(); got != tt.vendor {
+				t.Errorf("Vendor() = %q, se esperaba %q", got, tt.vendor)
+			}
+		})
+	}
+}
+
+func TestNormalizeMac(t *testing.T) {
+	tests := map[string]string{
+		"aa:bb:cc:dd:ee:ff": "AA-BB-CC-DD-EE-FF",
+		" aabb.ccdd.eeff ":  "AA-BB-CC-DD-EE-FF",
+		" no es una MAC ":   "no es una MAC",
+	}
+	for in, want := range tests {
+		if got := NormalizeMac(in); got != want {
+			t.Errorf("NormalizeMac(%q) = %q, se esperaba %q", in, got, want)
+		}
+	}
+}
+
+func TestSetMacInfoRandomOnlyWireless(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter NetworkAdapter
+		mac     string
+		random  bool
+	}{
+		{"virtio", NetworkAdapter{Name: "eth0", AdapterType: "virtio_net", HardwareType: "ethernet"}, "02-FC-00-00-00-01", false},
+		{"puente", NetworkAdapter{Name: "br0", HardwareType: "bridge"}, "DA-A1-19-6B-2F-04", false},
+		{"wifi nativo", NetworkAdapter{Name: "wlp2s0", HardwareType: "wireless"}, "DA-A1-19-6B-2F-04", true},
+		{"wifi getmac", NetworkAdapter{Name: "Wi-Fi", Source: AdapterSourceGetmac}, "DA-A1-19-6B-2F-04", true},
+		{"wifi de fabrica", NetworkAdapter{Name: "Wi-Fi", Source: AdapterSourceGetmac}, "3C-6A-A7-55-10-2E", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mac, err := ParseMAC(tt.mac)
+			if err != nil {
+				t.Fatal(err)
+			}
+			adapter := tt.adapter
+			setMacInfo(&adapter, mac)
+			if adapter.RandomMac != tt.random {
+				t.Errorf("RandomMac = %v, se esperaba %v", adapter.RandomMac, tt.random)
+			}
+		})
+	}
+}
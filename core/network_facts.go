@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Valores de NetworkFacts.Duplex.
+const (
+	DuplexFull = "full"
+	DuplexHalf = "half"
+)
+
+// NetworkFacts es la configuracion IP y de enlace del adaptador principal:
+// lo que hay que revisar cuando un equipo quedo en 100 Mb/s o con IP fija.
+// DHCPEnabled es nil y SpeedMbps 0 cuando no se pudieron determinar.
+type NetworkFacts struct {
+	Gateway       string
+	DNSServers    []string
+	DHCPEnabled   *bool
+	DHCPServer    string
+	LeaseObtained string
+	LeaseExpires  string
+	SpeedMbps     int64
+	Duplex        string
+}
+
+// GetNetworkFacts completa los datos de red del adaptador. Gateway,
+// velocidad y duplex vienen de la enumeracion; DNS y DHCP se consultan al
+// sistema solo para este adaptador. Lo que no se puede obtener queda vacio.
+func GetNetworkFacts(adapter NetworkAdapter) NetworkFacts {
+	facts := NetworkFacts{
+		Gateway:   adapter.Gateway,
+		SpeedMbps: adapter.SpeedMbps,
+		Duplex:    adapter.Duplex,
+	}
+
+	if adapter.Source == AdapterSourceNative {
+		loadNetworkFacts(currentRunner(), adapter, &facts)
+	}
+
+	return facts
+}
+
+func formatSpeed(mbps int64) string {
+	if mbps >= 1000 && mbps%1000 == 0 {
+		return strconv.FormatInt(mbps/1000, 10) + " Gb/s"
+	}
+	return strconv.FormatInt(mbps, 10) + " Mb/s"
+}
+
+// formatLeaseTime convierte un time_t en la fecha que se guarda con la
+// captura; 0 es "sin lease".
+func formatLeaseTime(unix int64) string {
+	if unix <= 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).Format("2006-01-02 15:04:05")
+}
+
+// parseFullDuplex interpreta la salida True/False de Get-NetAdapter.
+func parseFullDuplex(out string) string {
+	switch strings.ToLower(strings.TrimSpace(out)) {
+	case "true":
+		return DuplexFull
+	case "false":
+		return DuplexHalf
+	}
+	return ""
+}
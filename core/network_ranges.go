@@ -0,0 +1,244 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// NetworkRange es una red esperada para los equipos relevados. Name es
+// opcional; entre varios rangos que contienen la misma IP gana el de mayor
+// Priority, luego el mas especifico (mascara mas larga) y luego el primero
+// declarado.
+type NetworkRange struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	CIDR     string `json:"cidr" yaml:"cidr"`
+	Priority int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	network *net.IPNet
+}
+
+type networkRangesFile struct {
+	Ranges []NetworkRange `json:"ranges" yaml:"ranges"`
+}
+
+// AddressMatch es la IP elegida y el rango en el que cayo. Sin Matched la IP
+// no pertenece a ningun rango configurado.
+type AddressMatch struct {
+	IP      string
+	Range   NetworkRange
+	Matched bool
+}
+
+var (
+	rangesMu      sync.RWMutex
+	networkRanges []NetworkRange
+)
+
+// Label identifica el rango en logs y registros: "nombre (cidr)" o solo el
+// CIDR si no tiene nombre.
+func (r NetworkRange) Label() string {
+	if r.Name == "" {
+		return r.CIDR
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, r.CIDR)
+}
+
+// Contains indica si ip (IPv4 o IPv6) pertenece al rango.
+func (r NetworkRange) Contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && r.network != nil && r.network.Contains(parsed)
+}
+
+func (r NetworkRange) maskBits() int {
+	if r.network == nil {
+		return 0
+	}
+	ones, _ := r.network.Mask.Size()
+	return ones
+}
+
+// RangeLabel describe el resultado para mostrar al tecnico.
+func (m AddressMatch) RangeLabel() string {
+	if !m.Matched {
+		return "fuera de los rangos configurados"
+	}
+	return m.Range.Label()
+}
+
+// ParseNetworkRanges interpreta NETWORK_RANGES: CIDR separados por coma,
+// cada uno con un nombre opcional (Piso 1=10.1.1.0/24, 10.1.2.0/24).
+func ParseNetworkRanges(spec string) ([]NetworkRange, error) {
+	ranges := []NetworkRange{}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		r := NetworkRange{CIDR: item}
+		if name, cidr, ok := strings.Cut(item, "="); ok {
+			r.Name = strings.TrimSpace(name)
+			r.CIDR = strings.TrimSpace(cidr)
+		}
+		ranges = append(ranges, r)
+	}
+
+	return compileRanges(ranges, "NETWORK_RANGES")
+}
+
+// LoadNetworkRanges lee los rangos de un archivo JSON o YAML (segun la
+// extension) con la lista en "ranges".
+func LoadNetworkRanges(path string) ([]NetworkRange, error) {
+	var file networkRangesFile
+	if err := loadConfigFile(path, &file); err != nil {
+		return nil, fmt.Errorf("error leyendo rangos de red: %w", err)
+	}
+
+	return compileRanges(file.Ranges, path)
+}
+
+// RangeFromPrefix convierte el NETWORK_PREFIX anterior ("10.1.1" o
+// "10.1.1.") en el CIDR equivalente por octetos (10.1.1.0/24).
+func RangeFromPrefix(prefix string) (NetworkRange, error) {
+	octets := strings.Split(strings.TrimSuffix(strings.TrimSpace(prefix), "."), ".")
+	if len(octets) == 0 || len(octets) > 4 || octets[0] == "" {
+		return NetworkRange{}, fmt.Errorf("NETWORK_PREFIX invalido: %q", prefix)
+	}
+
+	bits := len(octets) * 8
+	for len(octets) < 4 {
+		octets = append(octets, "0")
+	}
+
+	ranges, err := compileRanges([]NetworkRange{{
+		CIDR: fmt.Sprintf("%s/%d", strings.Join(octets, "."), bits),
+	}}, "NETWORK_PREFIX")
+	if err != nil {
+		return NetworkRange{}, err
+	}
+	return ranges[0], nil
+}
+
+func compileRanges(ranges []NetworkRange, source string) ([]NetworkRange, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("%s no tiene rangos", source)
+	}
+
+	for i := range ranges {
+		_, network, err := net.ParseCIDR(ranges[i].CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rango %d: CIDR invalido %q", source, i+1, ranges[i].CIDR)
+		}
+		ranges[i].network = network
+		ranges[i].CIDR = network.String()
+	}
+
+	return ranges, nil
+}
+
+// SetNetworkRanges reemplaza los rangos activos.
+func SetNetworkRanges(ranges []NetworkRange) {
+	rangesMu.Lock()
+	networkRanges = ranges
+	rangesMu.Unlock()
+}
+
+// ActiveNetworkRanges devuelve los rangos en uso.
+func ActiveNetworkRanges() []NetworkRange {
+	rangesMu.RLock()
+	defer rangesMu.RUnlock()
+	return networkRanges
+}
+
+// MatchRange devuelve el rango de mayor prioridad que contiene ip.
+func MatchRange(ip string) (NetworkRange, bool) {
+	best := -1
+	ranges := ActiveNetworkRanges()
+
+	for i, r := range ranges {
+		if !r.Contains(ip) {
+			continue
+		}
+		if best < 0 || r.Priority > ranges[best].Priority ||
+			(r.Priority == ranges[best].Priority && r.maskBits() > ranges[best].maskBits()) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return NetworkRange{}, false
+	}
+	return ranges[best], true
+}
+
+// SelectAddress elige entre las IP candidatas la que cae en el mejor rango.
+// Si ninguna coincide devuelve la primera IPv4 utilizable y, sin IPv4, la
+// primera IPv6 global. Las de enlace local (169.254.x.x, fe80::) se ignoran.
+func SelectAddress(candidates []string) AddressMatch {
+	var best AddressMatch
+	fallback := ""
+
+	for _, ip := range candidates {
+		parsed := net.ParseIP(ip)
+		if parsed == nil || parsed.IsLoopback() || parsed.IsLinkLocalUnicast() {
+			continue
+		}
+		if fallback == "" || (parsed.To4() != nil && net.ParseIP(fallback).To4() == nil) {
+			fallback = ip
+		}
+
+		r, ok := MatchRange(ip)
+		if !ok {
+			continue
+		}
+		if !best.Matched || r.Priority > best.Range.Priority ||
+			(r.Priority == best.Range.Priority && r.maskBits() > best.Range.maskBits()) {
+			best = AddressMatch{IP: ip, Range: r, Matched: true}
+		}
+	}
+
+	if best.Matched {
+		return best
+	}
+	return AddressMatch{IP: fallback}
+}
+
+// AdapterAddress devuelve la IP del adaptador segun los rangos activos. Si
+// el adaptador no tiene direcciones (por ejemplo detectado con getmac) se
+// busca en todas las interfaces y fromSystem lo indica, porque la IP puede
+// ser de Wi-Fi o VPN.
+func AdapterAddress(adapter NetworkAdapter) (match AddressMatch, fromSystem bool, err error) {
+	if match = PreferredAddress(adapter); match.IP != "" {
+		return match, false, nil
+	}
+	match, err = SystemAddress()
+	return match, true, err
+}
+
+// SystemAddress elige la IP entre las de todas las interfaces del equipo.
+func SystemAddress() (AddressMatch, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return AddressMatch{}, fmt.Errorf("error obteniendo direcciones de red: %w", err)
+	}
+
+	candidates := []string{}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			candidates = append(candidates, ipnet.IP.String())
+		}
+	}
+
+	return SelectAddress(candidates), nil
+}
+
+// PreferredAddress elige la IP del adaptador segun los rangos activos,
+// prefiriendo IPv4 cuando ninguna coincide. IP queda vacia si el adaptador
+// no tiene direcciones utilizables.
+func PreferredAddress(adapter NetworkAdapter) AddressMatch {
+	candidates := append(append([]string{}, adapter.IPv4...), adapter.IPv6...)
+	return SelectAddress(candidates)
+}
@@ -0,0 +1,108 @@
+package core
+
+import "testing"
+
+// useTestRanges activa rangos de prueba y restaura los anteriores al
+// terminar el test.
+func useTestRanges(t *testing.T) {
+	t.Helper()
+
+	ranges, err := compileRanges([]NetworkRange{
+		{Name: "Red", CIDR: "10.0.0.0/8"},
+		{Name: "Piso 3", CIDR: "10.20.5.0/24"},
+		{Name: "VPN", CIDR: "10.20.5.128/25", Priority: -1},
+		{Name: "Gestion", CIDR: "192.168.0.0/16", Priority: 5},
+		{Name: "Duplicado", CIDR: "192.168.0.0/16", Priority: 5},
+		{Name: "IPv6", CIDR: "2001:db8::/32"},
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := ActiveNetworkRanges()
+	SetNetworkRanges(ranges)
+	t.Cleanup(func() { SetNetworkRanges(previous) })
+}
+
+func TestMatchRange(t *testing.T) {
+	useTestRanges(t)
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"10.1.1.1", "Red"},
+		{"10.20.5.10", "Piso 3"},
+		{"10.20.5.200", "Piso 3"},
+		{"192.168.1.1", "Gestion"},
+		{"2001:db8::1", "IPv6"},
+		{"172.16.0.1", ""},
+		{"no es una IP", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			r, ok := MatchRange(tt.ip)
+			if ok != (tt.want != "") || r.Name != tt.want {
+				t.Errorf("MatchRange(%s) = %q, %v; se esperaba %q", tt.ip, r.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectAddress(t *testing.T) {
+	useTestRanges(t)
+
+	tests := []struct {
+		name       string
+		candidates []string
+		ip         string
+		rango      string
+	}{
+		{"la que cae en un rango", []string{"172.16.0.1", "10.1.1.1"}, "10.1.1.1", "Red"},
+		{"el rango mas especifico", []string{"10.1.1.1", "10.20.5.10"}, "10.20.5.10", "Piso 3"},
+		{"la prioridad antes que la mascara", []string{"10.20.5.10", "192.168.1.1"}, "192.168.1.1", "Gestion"},
+		{"IPv6 dentro de un rango", []string{"172.16.0.1", "2001:db8::5"}, "2001:db8::5", "IPv6"},
+		{"sin rango prefiere IPv4", []string{"2001:db9::1", "172.16.0.1"}, "172.16.0.1", ""},
+		{"ignora enlace local", []string{"fe80::1", "169.254.1.1", "172.16.0.1"}, "172.16.0.1", ""},
+		{"solo IPv6 global", []string{"fe80::1", "2001:db9::1"}, "2001:db9::1", ""},
+		{"nada utilizable", []string{"127.0.0.1", "169.254.3.3", ""}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectAddress(tt.candidates)
+			if got.IP != tt.ip || got.Matched != (tt.rango != "") || got.Range.Name != tt.rango {
+				t.Errorf("SelectAddress(%v) = %s en %q (matched %v); se esperaba %s en %q",
+					tt.candidates, got.IP, got.Range.Name, got.Matched, tt.ip, tt.rango)
+			}
+		})
+	}
+}
+
+func TestRangeFromPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		want    string
+		wantErr bool
+	}{
+		{prefix: "10.1.1", want: "10.1.1.0/24"},
+		{prefix: "10.1.1.", want: "10.1.1.0/24"},
+		{prefix: "192.", want: "192.0.0.0/8"},
+		{prefix: "", wantErr: true},
+		{prefix: "10.1.1.1.1", wantErr: true},
+		{prefix: "10.300", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			r, err := RangeFromPrefix(tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RangeFromPrefix(%q): error %v", tt.prefix, err)
+			}
+			if r.CIDR != tt.want {
+				t.Errorf("RangeFromPrefix(%q) = %s, se esperaba %s", tt.prefix, r.CIDR, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package core
 
 import (
 	"fmt"
-	"os/exec"
-	"strings"
+	"strconv"
+	"time"
 )
 
 type NetworkAdapter struct {
@@ -15,78 +15,84 @@ type NetworkAdapter struct {
 	IsActive    bool
 	Speed       string
 	IPAddress   string
-}
-
-func GetAllNetworkAdapters() []NetworkAdapter {
-	adapters := []NetworkAdapter{}
-
-	cmd := exec.Command("getmac", "/v", "/fo", "csv")
-	out, err := cmd.Output()
-	if err != nil {
-		return adapters
-	}
 
-	lines := strings.Split(string(out), "\n")
+	Index        int
+	MTU          int
+	Flags        string
+	Addresses    []string
+	HardwareType string
+	Source       string
 
-	for i, line := range lines {
-		if i == 0 || line == "" {
-			continue
-		}
+	IPv4    []string
+	IPv6    []string
+	Gateway string
 
-		fields := strings.Split(line, ",")
-		if len(fields) < 4 {
-			continue
-		}
+	SpeedMbps int64
+	Duplex    string
 
-		for j := range fields {
-			fields[j] = strings.Trim(fields[j], "\"")
-			fields[j] = strings.TrimSpace(fields[j])
-		}
+	Vendor              string
+	LocallyAdministered bool
+	RandomMac           bool
 
-		adapter := NetworkAdapter{
-			Name:        fields[0],
-			AdapterType: fields[1],
-			MacAddress:  fields[2],
-			Status:      fields[3],
-		}
+	Physical bool
+	Rule     string
+}
 
-		nameLower := strings.ToLower(adapter.Name)
-		typeLower := strings.ToLower(adapter.AdapterType)
+// SameInterface indica si a y b son la misma interfaz. Se compara por
+// indice (o por nombre si vino de getmac, que no lo informa) y no por MAC,
+// porque vEthernet de Hyper-V y los bridges suelen copiar la MAC de la
+// placa fisica.
+func (a NetworkAdapter) SameInterface(b NetworkAdapter) bool {
+	if a.Index > 0 && b.Index > 0 {
+		return a.Index == b.Index
+	}
+	return a.Name == b.Name
+}
 
-		adapter.IsActive = !strings.Contains(strings.ToLower(adapter.Status), "disconnected") &&
-			!strings.Contains(strings.ToLower(adapter.Status), "desconectados")
+// Motivos por los que se eligio el adaptador principal. Se guardan con la
+// captura.
+const (
+	SelectionSingle     = "unico"
+	SelectionUnattended = "desatendido"
+	SelectionConfirmed  = "confirmado"
+	SelectionUser       = "usuario"
+	SelectionTimeout    = "timeout"
+)
 
-		adapter.IsEthernet = (strings.Contains(nameLower, "ethernet") ||
-			strings.Contains(typeLower, "ethernet") ||
-			strings.Contains(typeLower, "realtek") ||
-			strings.Contains(typeLower, "intel") ||
-			strings.Contains(typeLower, "broadcom")) &&
-			!strings.Contains(nameLower, "wi-fi") &&
-			!strings.Contains(nameLower, "wireless") &&
-			!strings.Contains(nameLower, "vmware") &&
-			!strings.Contains(nameLower, "virtualbox") &&
-			!strings.Contains(typeLower, "virtual")
+// DefaultSelectionTimeout es lo que se espera la respuesta del tecnico antes
+// de usar la eleccion automatica.
+const DefaultSelectionTimeout = 20 * time.Second
 
-		if isValidMacFormat(adapter.MacAddress) {
-			adapters = append(adapters, adapter)
-		}
-	}
+// SelectionOptions controla la pregunta al tecnico. Sin Interactive se usa
+// directamente la eleccion automatica.
+type SelectionOptions struct {
+	Interactive bool
+	Timeout     time.Duration
+}
 
-	return adapters
+// AdapterSelection es el adaptador elegido y por que.
+type AdapterSelection struct {
+	Adapter NetworkAdapter
+	Reason  string
 }
 
 func GetEthernetMacWithConfirmation() (string, error) {
-	adapters := GetAllNetworkAdapters()
-
-	ethernetAdapters := []NetworkAdapter{}
-	for _, adapter := range adapters {
-		if adapter.IsEthernet {
-			ethernetAdapters = append(ethernetAdapters, adapter)
-		}
+	selection, err := SelectEthernetAdapter(SelectionOptions{Interactive: true, Timeout: DefaultSelectionTimeout})
+	if err != nil {
+		return "", err
 	}
+	return selection.Adapter.MacAddress, nil
+}
+
+// SelectEthernetAdapter elige el adaptador Ethernet del relevamiento y lo
+// devuelve completo, para que MAC, IP, gateway y estado salgan del mismo
+// adaptador. Si hay mas de uno y la sesion es interactiva, pregunta al
+// tecnico proponiendo la eleccion automatica (uno conectado con IPv4).
+func SelectEthernetAdapter(opts SelectionOptions) (AdapterSelection, error) {
+	ethernetAdapters := ethernetAdapters()
 
 	if len(ethernetAdapters) == 0 {
-		return "", fmt.Errorf("no se encontraron adaptadores Ethernet")
+		return AdapterSelection{}, fmt.Errorf("no se encontraron adaptadores Ethernet")
 	}
 
 	fmt.Println("\nAdaptadores Ethernet detectados:")
@@ -96,18 +102,101 @@ func GetEthernetMacWithConfirmation() (string, error) {
 			status = "Conectado"
 		}
 
-		fmt.Printf("  [%d] %s - %s\n", i+1, adapter.MacAddress, status)
+		fmt.Printf("  [%d] %s - %s - %s\n", i+1, adapter.Name, adapter.MacAddress, status)
+		fmt.Printf("      %s | %s | IP: %s\n",
+			valueOrUnknown(adapter.AdapterType), valueOrUnknown(adapter.Vendor), valueOrNone(adapter.IPAddress))
+	}
+
+	def := defaultAdapterIndex(ethernetAdapters)
+	if !ethernetAdapters[def].IsActive {
+		fmt.Printf("\n[!] Sin adaptadores activos, se propone: %s\n", ethernetAdapters[def].MacAddress)
+	}
+
+	selection := AdapterSelection{Adapter: ethernetAdapters[def], Reason: SelectionUnattended}
+	switch {
+	case len(ethernetAdapters) == 1:
+		selection.Reason = SelectionSingle
+	case opts.Interactive:
+		idx, reason := promptAdapter(len(ethernetAdapters), def, opts.Timeout)
+		selection = AdapterSelection{Adapter: ethernetAdapters[idx], Reason: reason}
 	}
 
-	for _, adapter := range ethernetAdapters {
+	fmt.Printf("\nMAC seleccionada: %s (%s)\n", selection.Adapter.MacAddress, selection.Adapter.Name)
+	return selection, nil
+}
+
+func ethernetAdapters() []NetworkAdapter {
+	adapters := []NetworkAdapter{}
+	for _, adapter := range GetAllNetworkAdapters() {
+		if adapter.IsEthernet {
+			adapters = append(adapters, adapter)
+		}
+	}
+	return adapters
+}
+
+// defaultAdapterIndex es la eleccion automatica: conectado con IPv4, luego
+// conectado, luego el primero.
+func defaultAdapterIndex(adapters []NetworkAdapter) int {
+	for i, adapter := range adapters {
+		if adapter.IsActive && len(adapter.IPv4) > 0 {
+			return i
+		}
+	}
+	for i, adapter := range adapters {
 		if adapter.IsActive {
-			fmt.Printf("\nMAC seleccionada: %s\n", adapter.MacAddress)
-			return adapter.MacAddress, nil
+			return i
 		}
 	}
+	return 0
+}
+
+func promptAdapter(count, def int, timeout time.Duration) (int, string) {
+	if timeout <= 0 {
+		timeout = DefaultSelectionTimeout
+	}
+	deadline := time.Now().Add(timeout)
 
-	fmt.Printf("\n[!] Sin adaptadores activos, usando: %s\n", ethernetAdapters[0].MacAddress)
-	return ethernetAdapters[0].MacAddress, nil
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+
+		fmt.Printf("\nAdaptador a usar [1-%d] (Enter = %d, automatico en %ds): ", count, def+1, int(remaining.Round(time.Second).Seconds()))
+		line, ok := ReadConsoleLine(remaining)
+		if !ok {
+			break
+		}
+
+		if line == "" {
+			return def, SelectionConfirmed
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= count {
+			if n-1 == def {
+				return def, SelectionConfirmed
+			}
+			return n - 1, SelectionUser
+		}
+		fmt.Println("[X] Opcion invalida")
+	}
+
+	fmt.Printf("\n[!] Sin respuesta, se usa el adaptador %d\n", def+1)
+	return def, SelectionTimeout
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "sin IP"
+	}
+	return value
 }
 
 func ValidateNetworkConfiguration() error {
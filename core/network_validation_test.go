@@ -0,0 +1,27 @@
+package core
+
+import "testing"
+
+func TestSameInterface(t *testing.T) {
+	physical := NetworkAdapter{Name: "Ethernet", Index: 7, MacAddress: "00-1A-2B-3C-4D-5E"}
+
+	tests := []struct {
+		name  string
+		other NetworkAdapter
+		want  bool
+	}{
+		{"misma interfaz", NetworkAdapter{Name: "Ethernet", Index: 7, MacAddress: "00-1A-2B-3C-4D-5E"}, true},
+		{"vEthernet con la misma MAC", NetworkAdapter{Name: "vEthernet (Default Switch)", Index: 12, MacAddress: "00-1A-2B-3C-4D-5E"}, false},
+		{"renombrada con el mismo indice", NetworkAdapter{Name: "Ethernet 2", Index: 7}, true},
+		{"getmac sin indice", NetworkAdapter{Name: "Ethernet"}, true},
+		{"getmac otro nombre", NetworkAdapter{Name: "Ethernet 2", MacAddress: "00-1A-2B-3C-4D-5E"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.other.SameInterface(physical); got != tt.want {
+				t.Errorf("SameInterface() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
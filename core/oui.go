@@ -0,0 +1,94 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// oui_parcial.csv tiene el formato del registro publico del IEEE
+// (https://standards-oui.ieee.org/oui/oui.csv) pero solo unos 70 prefijos:
+// los fabricantes de adaptadores que aparecen en el parque (Intel, Dell, HP,
+// Realtek) y los de virtualizacion. Un fabricante vacio puede ser una MAC
+// valida que no esta en la tabla. Para usar el registro completo se descarga
+// ese archivo y se indica en OUI_FILE.
+//
+//go:embed oui_parcial.csv
+var embeddedOUI string
+
+var (
+	ouiMu    sync.RWMutex
+	ouiTable map[string]string
+)
+
+// LookupOUI devuelve el fabricante de un prefijo AABBCC, o "" si no figura
+// en la tabla cargada.
+func LookupOUI(oui string) string {
+	ouiMu.RLock()
+	table := ouiTable
+	ouiMu.RUnlock()
+
+	if table == nil {
+		ouiMu.Lock()
+		if ouiTable == nil {
+			ouiTable, _ = parseOUI(strings.NewReader(embeddedOUI))
+		}
+		table = ouiTable
+		ouiMu.Unlock()
+	}
+
+	return table[strings.ToUpper(oui)]
+}
+
+// LoadOUIFile reemplaza la tabla embebida por un oui.csv del IEEE.
+func LoadOUIFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error abriendo tabla OUI: %w", err)
+	}
+	defer f.Close()
+
+	table, err := parseOUI(f)
+	if err != nil {
+		return fmt.Errorf("tabla OUI %s invalida: %w", path, err)
+	}
+	if len(table) == 0 {
+		return fmt.Errorf("tabla OUI %s sin registros", path)
+	}
+
+	ouiMu.Lock()
+	ouiTable = table
+	ouiMu.Unlock()
+	return nil
+}
+
+func parseOUI(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	table := map[string]string{}
+	for i := 0; ; i++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || len(fields) < 3 {
+			continue
+		}
+
+		assignment := strings.ToUpper(strings.TrimSpace(fields[1]))
+		if len(assignment) != 6 {
+			continue
+		}
+		table[assignment] = strings.TrimSpace(fields[2])
+	}
+
+	return table, nil
+}
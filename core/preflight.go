@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Resultado de cada verificacion del preflight.
+const (
+	CheckPass = "OK"
+	CheckWarn = "ADVERTENCIA"
+	CheckFail = "FALLA"
+)
+
+// PreflightCheck es una linea del checklist de red.
+type PreflightCheck struct {
+	Name     string
+	Status   string
+	Detail   string
+	Duration time.Duration
+}
+
+// PreflightConfig indica el servidor de base a verificar. Sin DBHost se
+// omiten esas verificaciones (SQLite o memoria). La IP se compara con los
+// rangos activos. Adapter es el adaptador que eligio la captura; sin el se
+// usa la eleccion automatica de SelectEthernetAdapter.
+type PreflightConfig struct {
+	DBHost  string
+	DBPort  string
+	Timeout time.Duration
+	Adapter *NetworkAdapter
+}
+
+// RunPreflight revisa la red en el orden en que suelen fallar: cable, IP,
+// gateway, DNS y servidor. Sirve para distinguir un problema de cableado de
+// uno del servidor antes de intentar guardar.
+func RunPreflight(cfg PreflightConfig) []PreflightCheck {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	checks := []PreflightCheck{}
+
+	adapter, found, linkCheck := checkWiredLink(cfg.Adapter)
+	checks = append(checks, linkCheck)
+	if found {
+		checks = append(checks, checkIPAddress(adapter))
+		checks = append(checks, checkGateway(adapter))
+	}
+
+	if cfg.DBHost != "" {
+		checks = append(checks, checkDNS(cfg.DBHost, cfg.Timeout))
+		checks = append(checks, checkTCP(cfg.DBHost, cfg.DBPort, cfg.Timeout))
+	}
+
+	return checks
+}
+
+// PreflightFailed indica si alguna verificacion fallo.
+func PreflightFailed(checks []PreflightCheck) bool {
+	for _, c := range checks {
+		if c.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWiredLink verifica el enlace del adaptador elegido. found es false si
+// no hay ningun adaptador Ethernet, y entonces no se revisan IP ni gateway.
+func checkWiredLink(selected *NetworkAdapter) (NetworkAdapter, bool, PreflightCheck) {
+	check := PreflightCheck{Name: "Enlace cableado"}
+
+	var adapter NetworkAdapter
+	if selected != nil {
+		adapter = *selected
+	} else {
+		adapters := ethernetAdapters()
+		if len(adapters) == 0 {
+			check.Status = CheckFail
+			check.Detail = "no se detecto ningun adaptador Ethernet"
+			return NetworkAdapter{}, false, check
+		}
+		adapter = adapters[defaultAdapterIndex(adapters)]
+	}
+
+	check.Detail = fmt.Sprintf("%s (%s)", adapter.Name, adapter.MacAddress)
+	if !adapter.IsActive {
+		check.Status = CheckFail
+		check.Detail = "no hay cable Ethernet conectado en " + check.Detail
+		return adapter, true, check
+	}
+
+	check.Status = CheckPass
+	if adapter.Speed != "" {
+		check.Detail += " " + adapter.Speed
+	}
+	return adapter, true, check
+}
+
+func checkIPAddress(adapter NetworkAdapter) PreflightCheck {
+	check := PreflightCheck{Name: "IP en la red esperada"}
+
+	match, fromSystem, err := AdapterAddress(adapter)
+	switch {
+	case err != nil:
+		check.Status = CheckFail
+		check.Detail = err.Error()
+	case match.IP == "":
+		check.Status = CheckFail
+		check.Detail = "el adaptador no tiene IPv4"
+		for _, addr := range adapter.IPv4 {
+			if strings.HasPrefix(addr, "169.254.") {
+				check.Detail = fmt.Sprintf("IP automatica %s: el DHCP no respondio", addr)
+			}
+		}
+	case !match.Matched:
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("%s %s", match.IP, match.RangeLabel())
+	case fromSystem:
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("%s en %s, de otra interfaz y no de %s", match.IP, match.RangeLabel(), adapter.Name)
+	default:
+		check.Status = CheckPass
+		check.Detail = fmt.Sprintf("%s en %s", match.IP, match.RangeLabel())
+	}
+
+	return check
+}
+
+func checkGateway(adapter NetworkAdapter) PreflightCheck {
+	check := PreflightCheck{Name: "Gateway por defecto"}
+
+	if adapter.Gateway == "" {
+		check.Status = CheckWarn
+		check.Detail = "el adaptador no tiene gateway"
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Detail = adapter.Gateway
+	return check
+}
+
+func checkDNS(host string, timeout time.Duration) PreflightCheck {
+	check := PreflightCheck{Name: "DNS de DB_HOST"}
+
+	if ip := net.ParseIP(host); ip != nil {
+		check.Status = CheckPass
+		check.Detail = fmt.Sprintf("%s es una IP, no requiere DNS", host)
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	check.Duration = time.Since(start)
+
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("no se pudo resolver %s: %v", host, err)
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Detail = fmt.Sprintf("%s -> %s", host, strings.Join(addrs, ", "))
+	return check
+}
+
+func checkTCP(host, port string, timeout time.Duration) PreflightCheck {
+	check := PreflightCheck{Name: "Conexion a la base"}
+
+	address := net.JoinHostPort(host, port)
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	check.Duration = time.Since(start)
+
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s: %v", address, err)
+		return check
+	}
+	conn.Close()
+
+	check.Status = CheckPass
+	check.Detail = address
+	if check.Duration > time.Second {
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("%s responde lento", address)
+	}
+	return check
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandRunner ejecuta los comandos externos de los colectores. Cada
+// colector lo recibe como parametro, lo que permite reemplazar exec.Command
+// por salidas grabadas para probar los parsers fuera de Windows.
+type CommandRunner interface {
+	Run(name string, args ...string) ([]byte, error)
+}
+
+// ContextRunner es un CommandRunner que ademas puede cortar el comando
+// cuando vence ctx. Ver runWithTimeout.
+type ContextRunner interface {
+	RunContext(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// ExecRunner ejecuta el comando real y devuelve su stdout.
+type ExecRunner struct{}
+
+func (r ExecRunner) Run(name string, args ...string) ([]byte, error) {
+	return r.RunContext(context.Background(), name, args...)
+}
+
+func (ExecRunner) RunContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Si el proceso deja hijos con la salida abierta, no esperar por ellos.
+	cmd.WaitDelay = 2 * time.Second
+	return cmd.Output()
+}
+
+var (
+	runnerMu      sync.RWMutex
+	commandRunner CommandRunner = ExecRunner{}
+)
+
+// SetCommandRunner reemplaza el runner de los colectores publicos
+// (GetSystemInfoReport, GetBIOSInfo, GetDomainInfo, GetNetworkFacts y el
+// respaldo getmac de GetAllNetworkAdapters). nil vuelve a ExecRunner.
+func SetCommandRunner(r CommandRunner) {
+	if r == nil {
+		r = ExecRunner{}
+	}
+	runnerMu.Lock()
+	commandRunner = r
+	runnerMu.Unlock()
+}
+
+func currentRunner() CommandRunner {
+	runnerMu.RLock()
+	defer runnerMu.RUnlock()
+	return commandRunner
+}
+
+// runWithTimeout ejecuta el comando con un limite de tiempo si el runner lo
+// admite. Sirve para PowerShell y CIM, que pueden quedar colgados con el
+// repositorio WMI danado.
+func runWithTimeout(r CommandRunner, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	cr, ok := r.(ContextRunner)
+	if !ok {
+		return r.Run(name, args...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	out, err := cr.RunContext(ctx, name, args...)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s no respondio en %s", name, timeout)
+	}
+	return out, err
+}
+
+// FixtureKey arma el nombre de archivo de una salida grabada a partir de la
+// linea de comando: "wmic bios get version" -> "wmic_bios_get_version".
+func FixtureKey(name string, args ...string) string {
+	parts := append([]string{name}, args...)
+	key := strings.ToLower(strings.Join(parts, "_"))
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// FixtureRunner reproduce salidas grabadas en un directorio: <clave>.txt es
+// el stdout del comando y <clave>.err, si existe, simula que fallo con ese
+// mensaje. Un comando sin archivo falla como si no existiera.
+type FixtureRunner struct {
+	Dir string
+}
+
+func NewFixtureRunner(dir string) *FixtureRunner {
+	return &FixtureRunner{Dir: dir}
+}
+
+func (r *FixtureRunner) Run(name string, args ...string) ([]byte, error) {
+	key := FixtureKey(name, args...)
+
+	if msg, err := os.ReadFile(filepath.Join(r.Dir, key+".err")); err == nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(msg)))
+	}
+
+	out, err := os.ReadFile(filepath.Join(r.Dir, key+".txt"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, exec.ErrNotFound)
+	}
+	return out, nil
+}
+
+// RecordingRunner ejecuta los comandos con Runner y guarda cada salida en
+// Dir con el formato que lee FixtureRunner, para ampliar el corpus con
+// equipos reales.
+type RecordingRunner struct {
+	Runner CommandRunner
+	Dir    string
+}
+
+func (r *RecordingRunner) Run(name string, args ...string) ([]byte, error) {
+	return r.RunContext(context.Background(), name, args...)
+}
+
+func (r *RecordingRunner) RunContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var (
+		out    []byte
+		runErr error
+	)
+	if cr, ok := r.Runner.(ContextRunner); ok {
+		out, runErr = cr.RunContext(ctx, name, args...)
+	} else {
+		out, runErr = r.Runner.Run(name, args...)
+	}
+	if ctx.Err() != nil {
+		runErr = fmt.Errorf("%s no respondio: %v", name, ctx.Err())
+	}
+
+	key := FixtureKey(name, args...)
+	if err := os.MkdirAll(r.Dir, 0755); err == nil {
+		if runErr != nil {
+			os.WriteFile(filepath.Join(r.Dir, key+".err"), []byte(runErr.Error()+"\n"), 0644)
+		} else {
+			os.WriteFile(filepath.Join(r.Dir, key+".txt"), out, 0644)
+		}
+	}
+
+	return out, runErr
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWithTimeoutKillsHungCommand(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep no disponible")
+	}
+
+	start := time.Now()
+	_, err := runWithTimeout(ExecRunner{}, 200*time.Millisecond, "sleep", "10")
+	if err == nil || !strings.Contains(err.Error(), "no respondio") {
+		t.Fatalf("se esperaba error de timeout, se obtuvo %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("el comando no se corto a tiempo: %s", elapsed)
+	}
+}
+
+func TestRunWithTimeoutPlainRunner(t *testing.T) {
+	r := NewFixtureRunner("testdata/commands/es-windows10")
+	out, err := runWithTimeout(r, time.Second, "wmic", "bios", "get", "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "Version") {
+		t.Errorf("salida inesperada: %q", out)
+	}
+}
+
+type countingRunner struct {
+	calls []string
+}
+
+func (r *countingRunner) Run(name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, FixtureKey(name, args...))
+	return nil, exec.ErrNotFound
+}
+
+func TestSetCommandRunner(t *testing.T) {
+	r := &countingRunner{}
+	SetCommandRunner(r)
+	defer SetCommandRunner(nil)
+
+	GetDomainInfo()
+	if len(r.calls) == 0 {
+		t.Fatal("GetDomainInfo no uso el runner configurado")
+	}
+
+	SetCommandRunner(nil)
+	if _, ok := currentRunner().(ExecRunner); !ok {
+		t.Errorf("nil deberia volver a ExecRunner, quedo %T", currentRunner())
+	}
+}
@@ -0,0 +1,305 @@
+//go:build linux
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var (
+	procCPUInfo    = "/proc/cpuinfo"
+	procMemInfo    = "/proc/meminfo"
+	kernelRelease  = "/proc/sys/kernel/osrelease"
+	osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+	dmiDir         = "/sys/class/dmi/id"
+	sssdConfPath   = "/etc/sssd/sssd.conf"
+)
+
+// GetSystemInfoReport releva el sistema desde /etc/os-release, /proc y la
+// tabla DMI de /sys, e informa que campos no se resolvieron.
+func GetSystemInfoReport() (SystemInfo, SystemInfoReport) {
+	info := SystemInfo{
+		Architecture: runtime.GOARCH,
+	}
+	report := SystemInfoReport{}
+
+	release, err := readOSRelease()
+	if err != nil {
+		report.unresolved("os", err.Error())
+	} else {
+		info.OS = release["PRETTY_NAME"]
+		if info.OS == "" {
+			info.OS = strings.TrimSpace(release["NAME"] + " " + release["VERSION"])
+		}
+		info.Version = release["VERSION_ID"]
+	}
+	if kernel := readSysfsValue(filepath.Dir(kernelRelease), filepath.Base(kernelRelease)); kernel != "" {
+		info.Version = strings.TrimSpace(info.Version + " (kernel " + kernel + ")")
+	}
+	if info.Version == "" {
+		report.unresolved("version", "sin VERSION_ID ni version de kernel")
+	}
+
+	if mb, err := readMemTotalMB(); err != nil {
+		report.unresolved("memory_ram", err.Error())
+	} else {
+		info.MemoryRAM = fmt.Sprintf("%d MB", mb)
+	}
+
+	info.Processors = readCPUModels()
+	if len(info.Processors) > 0 {
+		info.Processor = info.Processors[0]
+	} else {
+		info.Processor = "Desconocido"
+		report.unresolved("processor", fmt.Sprintf("%s no informa el modelo", procCPUInfo))
+	}
+
+	info.Manufacturer = readSysfsValue(dmiDir, "sys_vendor")
+	if info.Manufacturer == "" {
+		report.unresolved("manufacturer", fmt.Sprintf("sin %s/sys_vendor", dmiDir))
+	}
+	info.Model = readSysfsValue(dmiDir, "product_name")
+	if info.Model == "" {
+		report.unresolved("model", fmt.Sprintf("sin %s/product_name", dmiDir))
+	}
+
+	info.CurrentUser = linuxCurrentUser(currentRunner())
+
+	return info, report
+}
+
+// GetBIOSInfo lee numero de serie y version de la BIOS de la tabla DMI.
+// product_serial solo lo puede leer root.
+func GetBIOSInfo() (string, string) {
+	return readSysfsValue(dmiDir, "product_serial"), readSysfsValue(dmiDir, "bios_version")
+}
+
+// GetDomainInfo informa el dominio en el que el equipo esta unido con
+// realmd y, si realm no esta instalado, el de la configuracion de sssd.
+func GetDomainInfo() DomainInfo {
+	if info, ok := realmDomainInfo(currentRunner()); ok {
+		return info
+	}
+	return sssdDomainInfo()
+}
+
+// GetDomainInfoAlternative toma el dominio de la configuracion de sssd.
+func GetDomainInfoAlternative() DomainInfo {
+	return sssdDomainInfo()
+}
+
+func newDomainInfo(domain string) DomainInfo {
+	domain = strings.TrimSpace(domain)
+	if domain == "" {
+		return DomainInfo{}
+	}
+	return DomainInfo{
+		EnDominio:     true,
+		NombreDominio: domain,
+		EsMecLocal:    strings.EqualFold(domain, "mec.local"),
+	}
+}
+
+// realmDomainInfo interpreta "realm list": cada realm empieza en una linea
+// sin sangria y sus propiedades van debajo. Cuenta el primero con
+// "configured" distinto de "no".
+func realmDomainInfo(r CommandRunner) (DomainInfo, bool) {
+	out, err := r.Run("realm", "list")
+	if err != nil {
+		return DomainInfo{}, false
+	}
+
+	name, domain, configured := "", "", false
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			if configured {
+				break
+			}
+			name, domain = strings.TrimSpace(line), ""
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "domain-name":
+			domain = strings.TrimSpace(value)
+		case "configured":
+			configured = strings.TrimSpace(value) != "no"
+		}
+	}
+
+	if !configured {
+		return DomainInfo{}, true
+	}
+	if domain == "" {
+		domain = name
+	}
+	return newDomainInfo(domain), true
+}
+
+// sssdDomainInfo toma el primer dominio de "domains" en la seccion [sssd]
+// o, si no esta, la primera seccion [domain/...].
+func sssdDomainInfo() DomainInfo {
+	data, err := os.ReadFile(sssdConfPath)
+	if err != nil {
+		return DomainInfo{}
+	}
+
+	section, firstSection := "", ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if firstSection == "" && strings.HasPrefix(section, "domain/") {
+				firstSection = strings.TrimPrefix(section, "domain/")
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if section == "sssd" && ok && strings.TrimSpace(key) == "domains" {
+			if domains := strings.Split(value, ","); strings.TrimSpace(domains[0]) != "" {
+				return newDomainInfo(domains[0])
+			}
+		}
+	}
+
+	return newDomainInfo(firstSection)
+}
+
+func readOSRelease() (map[string]string, error) {
+	var lastErr error
+	for _, path := range osReleasePaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		values := map[string]string{}
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok || strings.HasPrefix(key, "#") {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			values[key] = strings.Trim(value, "'")
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("no se pudo leer os-release: %v", lastErr)
+}
+
+func readMemTotalMB() (int64, error) {
+	data, err := os.ReadFile(procMemInfo)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("MemTotal invalido: %q", fields[1])
+			}
+			return kb / 1024, nil
+		}
+	}
+	return 0, fmt.Errorf("%s no tiene MemTotal", procMemInfo)
+}
+
+// readCPUModels devuelve el modelo de cada procesador fisico (no de cada
+// nucleo). En ARM el modelo suele venir en "Model" o "Hardware".
+func readCPUModels() []string {
+	data, err := os.ReadFile(procCPUInfo)
+	if err != nil {
+		return nil
+	}
+
+	models := []string{}
+	seen := map[string]bool{}
+	model, physical := "", ""
+	fallback := ""
+
+	flush := func() {
+		if model != "" && !seen[physical] {
+			seen[physical] = true
+			models = append(models, model)
+		}
+		model, physical = "", ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			if strings.TrimSpace(line) == "" {
+				flush()
+			}
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "model name":
+			model = value
+		case "physical id":
+			physical = value
+		case "Model", "Hardware":
+			if fallback == "" {
+				fallback = value
+			}
+		}
+	}
+	flush()
+
+	if len(models) == 0 && fallback != "" {
+		models = append(models, fallback)
+	}
+	return models
+}
+
+// linuxCurrentUser devuelve el usuario del equipo y no root: el que invoco
+// sudo o, si se ejecuta como root, el primero con sesion segun "who".
+func linuxCurrentUser(r CommandRunner) string {
+	if u := os.Getenv("SUDO_USER"); u != "" && u != "root" {
+		return u
+	}
+
+	current, err := user.Current()
+	if err == nil && current.Uid != "0" {
+		return current.Username
+	}
+
+	if out, err := r.Run("who"); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] != "root" {
+				return fields[0]
+			}
+		}
+	}
+
+	if err == nil {
+		return current.Username
+	}
+	return "Desconocido"
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package core
+
+// GetSystemInfoReport releva el sistema con systeminfo, CIM y wmic.
+func GetSystemInfoReport() (SystemInfo, SystemInfoReport) {
+	return windowsSystemInfoReport(currentRunner())
+}
+
+// GetBIOSInfo devuelve numero de serie y version de la BIOS.
+func GetBIOSInfo() (string, string) {
+	return windowsBIOSInfo(currentRunner())
+}
+
+// GetDomainInfo informa si el equipo esta en un dominio.
+func GetDomainInfo() DomainInfo {
+	return windowsDomainInfo(currentRunner())
+}
+
+// GetDomainInfoAlternative es la segunda fuente del dominio (systeminfo).
+func GetDomainInfoAlternative() DomainInfo {
+	return windowsDomainInfoAlternative(currentRunner())
+}
@@ -1,8 +1,10 @@
 package core
 
 import (
-	"os/exec"
+	"fmt"
+	"os"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -17,89 +19,105 @@ type SystemInfo struct {
 	Model        string
 	SerialNumber string
 	BIOSVersion  string
+
+	Processors []string
+	Hotfixes   []string
 }
 
+// GetSystemInfo releva el sistema; ver GetSystemInfoReport.
 func GetSystemInfo() SystemInfo {
-	info := SystemInfo{
-		Architecture: runtime.GOARCH,
+	info, _ := GetSystemInfoReport()
+	return info
+}
+
+// ComputerName devuelve el nombre del equipo: COMPUTERNAME en Windows y, si
+// no esta definida, el hostname sin el dominio.
+func ComputerName() string {
+	if name := os.Getenv("COMPUTERNAME"); name != "" {
+		return name
 	}
 
-	cmd := exec.Command("systeminfo")
-	out, err := cmd.Output()
+	host, err := os.Hostname()
 	if err != nil {
-		return info
+		return ""
 	}
+	if i := strings.IndexByte(host, '.'); i > 0 {
+		host = host[:i]
+	}
+	return host
+}
 
-	lines := strings.Split(string(out), "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(line, "Nombre del sistema operativo:") ||
-			strings.HasPrefix(line, "OS Name:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				info.OS = strings.TrimSpace(parts[1])
-			}
-		}
-
-		if strings.HasPrefix(line, "Version del sistema operativo:") ||
-			strings.HasPrefix(line, "OS Version:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				info.Version = strings.TrimSpace(parts[1])
-			}
-		}
+// windowsSystemInfoReport lee systeminfo /FO CSV por posicion de columna,
+// sin depender del idioma de Windows, e informa que campos no se resolvieron.
+// El procesador se toma de CIM o wmic, que dan el nombre comercial;
+// systeminfo solo informa la familia y se usa si ambos fallan.
+func windowsSystemInfoReport(r CommandRunner) (SystemInfo, SystemInfoReport) {
+	info := SystemInfo{
+		Architecture: runtime.GOARCH,
+	}
+	report := SystemInfoReport{}
 
-		if strings.HasPrefix(line, "Memoria fisica total:") ||
-			strings.HasPrefix(line, "Total Physical Memory:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				info.MemoryRAM = strings.TrimSpace(parts[1])
-			}
+	row, err := runSystemInfoCSV(r)
+	if err != nil {
+		report.unresolved("systeminfo", err.Error())
+	} else {
+		report.Columns = len(row.values)
+
+		info.OS = row.resolve(&report, siColOSName, "os")
+		info.Version = row.resolve(&report, siColOSVersion, "version")
+		info.Manufacturer = row.resolve(&report, siColSystemManufacturer, "manufacturer")
+		info.Model = row.resolve(&report, siColSystemModel, "model")
+
+		info.MemoryRAM = row.resolve(&report, siColTotalPhysicalMemory, "memory_ram")
+		if info.MemoryRAM != "" && ParseMemoryMB(info.MemoryRAM) == 0 {
+			report.unresolved("memory_ram", fmt.Sprintf("valor no interpretable: %q", info.MemoryRAM))
 		}
 
-		if strings.HasPrefix(line, "Procesador(es):") ||
-			strings.HasPrefix(line, "Processor(s):") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				processorInfo := strings.TrimSpace(parts[1])
-				if !strings.Contains(processorInfo, "instalados") &&
-					!strings.Contains(processorInfo, "installed") {
-					info.Processor = processorInfo
-				}
-			}
-		}
+		info.Processors = multiValueItems(row.resolve(&report, siColProcessors, "processors"))
+		info.Hotfixes = multiValueItems(row.resolve(&report, siColHotfixes, "hotfixes"))
+	}
 
-		if strings.HasPrefix(line, "Fabricante del sistema:") ||
-			strings.HasPrefix(line, "System Manufacturer:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				info.Manufacturer = strings.TrimSpace(parts[1])
+	if (info.Manufacturer == "" || info.Model == "") && useCIM() {
+		if cs, ok := cimComputerSystemInfo(r); ok {
+			if info.Manufacturer == "" && cs.Manufacturer != "" {
+				info.Manufacturer = strings.TrimSpace(cs.Manufacturer)
+				report.resolvedBy("manufacturer", BackendCIM)
 			}
-		}
-
-		if strings.HasPrefix(line, "Modelo del sistema:") ||
-			strings.HasPrefix(line, "System Model:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				info.Model = strings.TrimSpace(parts[1])
+			if info.Model == "" && cs.Model != "" {
+				info.Model = strings.TrimSpace(cs.Model)
+				report.resolvedBy("model", BackendCIM)
 			}
 		}
 	}
 
-	if info.Processor == "" {
-		info.Processor = getProcessorWMIC()
+	info.Processor = getProcessorName(r)
+	if info.Processor == "Desconocido" && len(info.Processors) > 0 {
+		info.Processor = info.Processors[0]
+	}
+	if info.Processor == "Desconocido" {
+		report.unresolved("processor", "ni CIM, ni wmic ni systeminfo informaron el procesador")
 	}
 
-	info.CurrentUser = getRealUser()
+	info.CurrentUser = getRealUser(r)
 
-	return info
+	return info, report
+}
+
+// getProcessorName consulta Win32_Processor por CIM y, si no responde, wmic.
+func getProcessorName(r CommandRunner) string {
+	if useCIM() {
+		if name, ok := cimProcessorName(r); ok {
+			return name
+		}
+	}
+	if useWMIC() {
+		return getProcessorWMIC(r)
+	}
+	return "Desconocido"
 }
 
-func getProcessorWMIC() string {
-	cmd := exec.Command("wmic", "cpu", "get", "name")
-	out, err := cmd.Output()
+func getProcessorWMIC(r CommandRunner) string {
+	out, err := r.Run("wmic", "cpu", "get", "name")
 	if err != nil {
 		return "Desconocido"
 	}
@@ -116,15 +134,15 @@ func getProcessorWMIC() string {
 	return "Desconocido"
 }
 
-func getRealUser() string {
-	currentUser := getCurrentUser()
+func getRealUser(r CommandRunner) string {
+	currentUser := getCurrentUser(r)
 
 	if currentUser == "Desconocido" {
 		return currentUser
 	}
 
-	if isAdministrator(currentUser) {
-		lastUser := getLastNonAdminUser()
+	if isAdministrator(r, currentUser) {
+		lastUser := getLastNonAdminUser(r)
 		if lastUser != "" && lastUser != currentUser {
 			return lastUser
 		}
@@ -133,9 +151,8 @@ func getRealUser() string {
 	return currentUser
 }
 
-func getCurrentUser() string {
-	cmd := exec.Command("whoami")
-	out, err := cmd.Output()
+func getCurrentUser(r CommandRunner) string {
+	out, err := r.Run("whoami")
 	if err != nil {
 		return "Desconocido"
 	}
@@ -143,7 +160,7 @@ func getCurrentUser() string {
 	return strings.TrimSpace(string(out))
 }
 
-func isAdministrator(username string) bool {
+func isAdministrator(r CommandRunner, username string) bool {
 	username = strings.ToLower(username)
 
 	if strings.Contains(username, "administrador") ||
@@ -152,11 +169,9 @@ func isAdministrator(username string) bool {
 		return true
 	}
 
-	cmd := exec.Command("net", "localgroup", "administradores")
-	out, err := cmd.Output()
+	out, err := r.Run("net", "localgroup", "administradores")
 	if err != nil {
-		cmd = exec.Command("net", "localgroup", "administrators")
-		out, err = cmd.Output()
+		out, err = r.Run("net", "localgroup", "administrators")
 		if err != nil {
 			return false
 		}
@@ -172,30 +187,22 @@ func isAdministrator(username string) bool {
 	return strings.Contains(outputStr, strings.ToLower(userName))
 }
 
-func getLastNonAdminUser() string {
-	cmd := exec.Command("wmic", "netlogin", "get", "name")
-	out, err := cmd.Output()
-	if err != nil {
-		return getLastUserFromRegistry()
+func getLastNonAdminUser(r CommandRunner) string {
+	profiles, ok := loginProfiles(r)
+	if !ok {
+		return getLastUserFromRegistry(r)
 	}
 
-	lines := strings.Split(string(out), "\n")
 	users := make([]string, 0)
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		if i == 0 || line == "" || strings.ToLower(line) == "name" {
-			continue
-		}
-
-		if line != "" && !isSystemUser(line) {
-			users = append(users, line)
+	for _, name := range profiles {
+		if !isSystemUser(name) {
+			users = append(users, name)
 		}
 	}
 
 	for i := len(users) - 1; i >= 0; i-- {
 		user := users[i]
-		if !isAdministrator(user) {
+		if !isAdministrator(r, user) {
 			return user
 		}
 	}
@@ -203,11 +210,38 @@ func getLastNonAdminUser() string {
 	return ""
 }
 
-func getLastUserFromRegistry() string {
-	cmd := exec.Command("reg", "query",
+// loginProfiles lista los perfiles de inicio de sesion del equipo
+// (Win32_NetworkLoginProfile) por CIM o wmic.
+func loginProfiles(r CommandRunner) ([]string, bool) {
+	if useCIM() {
+		if names, ok := cimLoginProfiles(r); ok {
+			return names, true
+		}
+	}
+	if !useWMIC() {
+		return nil, false
+	}
+
+	out, err := r.Run("wmic", "netlogin", "get", "name")
+	if err != nil {
+		return nil, false
+	}
+
+	names := []string{}
+	for i, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 || line == "" || strings.ToLower(line) == "name" {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, true
+}
+
+func getLastUserFromRegistry(r CommandRunner) string {
+	out, err := r.Run("reg", "query",
 		"HKEY_LOCAL_MACHINE\\SOFTWARE\\Microsoft\\Windows\\CurrentVersion\\Authentication\\LogonUI",
 		"/v", "LastLoggedOnUser")
-	out, err := cmd.Output()
 	if err != nil {
 		return ""
 	}
@@ -218,7 +252,7 @@ func getLastUserFromRegistry() string {
 			parts := strings.Fields(line)
 			if len(parts) >= 3 {
 				lastUser := parts[len(parts)-1]
-				if !isSystemUser(lastUser) && !isAdministrator(lastUser) {
+				if !isSystemUser(lastUser) && !isAdministrator(r, lastUser) {
 					return lastUser
 				}
 			}
@@ -250,12 +284,22 @@ func isSystemUser(username string) bool {
 	return false
 }
 
-func GetBIOSInfo() (string, string) {
+// windowsBIOSInfo devuelve numero de serie y version de la BIOS (Win32_BIOS)
+// por CIM o wmic.
+func windowsBIOSInfo(r CommandRunner) (string, string) {
+	if useCIM() {
+		if bios, ok := cimBIOSInfo(r); ok {
+			return strings.TrimSpace(bios.SerialNumber), strings.TrimSpace(bios.Version)
+		}
+	}
+	if !useWMIC() {
+		return "", ""
+	}
+
 	serialNumber := ""
 	biosVersion := ""
 
-	cmdSerial := exec.Command("wmic", "bios", "get", "serialnumber")
-	outSerial, err := cmdSerial.Output()
+	outSerial, err := r.Run("wmic", "bios", "get", "serialnumber")
 	if err == nil {
 		lines := strings.Split(string(outSerial), "\n")
 		for i, line := range lines {
@@ -268,8 +312,7 @@ func GetBIOSInfo() (string, string) {
 		}
 	}
 
-	cmdVersion := exec.Command("wmic", "bios", "get", "version")
-	outVersion, err := cmdVersion.Output()
+	outVersion, err := r.Run("wmic", "bios", "get", "version")
 	if err == nil {
 		lines := strings.Split(string(outVersion), "\n")
 		for i, line := range lines {
@@ -284,3 +327,68 @@ func GetBIOSInfo() (string, string) {
 
 	return serialNumber, biosVersion
 }
+
+// ParseMemoryMB convierte la memoria reportada por systeminfo ("8.192 MB",
+// "16,234 MB") a megabytes. Devuelve 0 si no se puede interpretar.
+func ParseMemoryMB(memory string) int64 {
+	fields := strings.Fields(memory)
+	if len(fields) == 0 {
+		return 0
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, fields[0])
+
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	if len(fields) > 1 {
+		switch strings.ToUpper(fields[1]) {
+		case "GB":
+			value *= 1024
+		case "KB":
+			value /= 1024
+		}
+	}
+
+	return value
+}
+
+// NormalizeSerialNumber descarta los valores de relleno que dejan algunos
+// fabricantes en la BIOS, para no confundir equipos distintos al buscarlos
+// por numero de serie.
+func NormalizeSerialNumber(serial string) string {
+	serial = strings.TrimSpace(serial)
+
+	placeholders := []string{
+		"",
+		"0",
+		"none",
+		"n/a",
+		"default string",
+		"to be filled by o.e.m.",
+		"system serial number",
+		"not specified",
+		"not applicable",
+		"123456789",
+	}
+
+	lower := strings.ToLower(serial)
+	for _, p := range placeholders {
+		if lower == p {
+			return ""
+		}
+	}
+
+	if strings.Trim(serial, "0") == "" {
+		return ""
+	}
+
+	return serial
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Columnas de systeminfo /FO CSV. El encabezado viene traducido segun el
+// idioma de Windows pero el orden de las columnas es siempre el mismo, asi
+// que se lee por posicion.
+const (
+	siColHostName            = 0
+	siColOSName              = 1
+	siColOSVersion           = 2
+	siColSystemManufacturer  = 11
+	siColSystemModel         = 12
+	siColProcessors          = 14
+	siColBIOSVersion         = 15
+	siColTotalPhysicalMemory = 22
+	siColDomain              = 28
+	siColHotfixes            = 30
+)
+
+// UnresolvedField es un dato que no se pudo obtener de systeminfo.
+type UnresolvedField struct {
+	Field  string
+	Reason string
+}
+
+// SystemInfoReport resume la lectura de systeminfo: cuantas columnas trajo
+// y que campos quedaron sin resolver. Los que systeminfo no trajo pero se
+// completaron por otro backend salen de Unresolved y quedan en ResolvedBy.
+type SystemInfoReport struct {
+	Columns    int
+	Unresolved []UnresolvedField
+	ResolvedBy map[string]string `json:",omitempty"`
+}
+
+func (r *SystemInfoReport) unresolved(field, reason string) {
+	r.Unresolved = append(r.Unresolved, UnresolvedField{Field: field, Reason: reason})
+}
+
+func (r *SystemInfoReport) resolvedBy(field, backend string) {
+	kept := r.Unresolved[:0]
+	for _, u := range r.Unresolved {
+		if u.Field != field {
+			kept = append(kept, u)
+		}
+	}
+	r.Unresolved = kept
+
+	if r.ResolvedBy == nil {
+		r.ResolvedBy = map[string]string{}
+	}
+	r.ResolvedBy[field] = backend
+}
+
+// systemInfoRow es la fila de datos de systeminfo /FO CSV.
+type systemInfoRow struct {
+	values []string
+}
+
+// runSystemInfoCSV ejecuta systeminfo en formato CSV y devuelve la fila de
+// datos.
+func runSystemInfoCSV(r CommandRunner) (systemInfoRow, error) {
+	out, err := r.Run("systeminfo", "/FO", "CSV")
+	if err != nil {
+		return systemInfoRow{}, fmt.Errorf("error ejecutando systeminfo: %v", err)
+	}
+	return parseSystemInfoCSV(string(out))
+}
+
+// parseSystemInfoCSV acepta la salida con o sin encabezado (/NH). Los campos
+// con varios valores (procesadores, revisiones, placas de red) vienen en una
+// sola celda entre comillas y pueden incluir saltos de linea.
+func parseSystemInfoCSV(out string) (systemInfoRow, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return systemInfoRow{}, fmt.Errorf("salida CSV de systeminfo invalida: %v", err)
+	}
+
+	rows := [][]string{}
+	for _, r := range records {
+		if len(r) > 1 {
+			rows = append(rows, r)
+		}
+	}
+
+	if len(rows) == 0 {
+		return systemInfoRow{}, fmt.Errorf("systeminfo no devolvio datos")
+	}
+	return systemInfoRow{values: rows[len(rows)-1]}, nil
+}
+
+// field devuelve la columna i sin espacios; ok es false si la fila no la
+// tiene.
+func (r systemInfoRow) field(i int) (string, bool) {
+	if i >= len(r.values) {
+		return "", false
+	}
+	return strings.TrimSpace(r.values[i]), true
+}
+
+// resolve lee una columna y anota en el reporte si falta o esta vacia.
+func (r systemInfoRow) resolve(report *SystemInfoReport, i int, name string) string {
+	value, ok := r.field(i)
+	switch {
+	case !ok:
+		report.unresolved(name, fmt.Sprintf("la salida no tiene la columna %d", i+1))
+	case value == "" || isNotAvailable(value):
+		report.unresolved(name, "sin valor")
+		return ""
+	}
+	return value
+}
+
+// isNotAvailable reconoce los "N/A" y "N/D" con que systeminfo marca un dato
+// que no conoce.
+func isNotAvailable(value string) bool {
+	switch strings.ToUpper(value) {
+	case "N/A", "N/D", "N/V", "N/B", "N/C":
+		return true
+	}
+	return false
+}
+
+var multiValueItem = regexp.MustCompile(`^\[\d+\]:\s*`)
+
+// multiValueItems separa una celda como "2 Hotfix(s) Installed.,[01]:
+// KB5034467,[02]: KB5034765" en sus elementos. El texto inicial con la
+// cantidad se descarta; lo que no empieza con [NN]: se agrega al elemento
+// anterior (un nombre con coma o un detalle de la placa de red).
+func multiValueItems(cell string) []string {
+	items := []string{}
+
+	parts := strings.FieldsFunc(cell, func(r rune) bool { return r == ',' || r == '\n' })
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if loc := multiValueItem.FindStringIndex(part); loc != nil {
+			items = append(items, part[loc[1]:])
+			continue
+		}
+		if len(items) > 0 {
+			items[len(items)-1] += ", " + part
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"relevamiento/repository"
+)
+
+// errorKind clasifica los errores que llegan a main. Cada tipo tiene un
+// codigo de salida fijo para que los scripts de despliegue distingan, por
+// ejemplo, "sin Ethernet" de "base caida".
+type errorKind int
+
+const (
+	kindUnexpected errorKind = iota
+	kindUsage
+	kindSpooled
+	kindConfig
+	kindEnv
+	kindNoEthernet
+	kindNoIP
+	kindPreflight
+	kindDBConnection
+	kindDBInsert
+	kindDBVerification
+	kindDBMigration
+	kindDBQuery
+)
+
+type errorKindInfo struct {
+	code    int
+	message string
+}
+
+var errorKinds = map[errorKind]errorKindInfo{
+	kindUnexpected:     {1, "Error inesperado"},
+	kindUsage:          {2, "Uso incorrecto"},
+	kindSpooled:        {3, "Captura guardada en spool, pendiente de sincronizar"},
+	kindConfig:         {10, "Configuracion de ubicacion invalida o inexistente"},
+	kindEnv:            {11, "Entorno invalido (.env, variables o permisos)"},
+	kindNoEthernet:     {20, "No se detecto un adaptador Ethernet"},
+	kindNoIP:           {21, "No se pudo obtener una IP valida"},
+	kindPreflight:      {22, "El diagnostico de red encontro fallas"},
+	kindDBConnection:   {30, "No se pudo conectar a la base de datos"},
+	kindDBInsert:       {31, "No se pudo guardar el registro en la base de datos"},
+	kindDBVerification: {32, "El registro no pudo verificarse despues de guardarlo"},
+	kindDBMigration:    {33, "Error aplicando migraciones"},
+	kindDBQuery:        {34, "No se pudo consultar la base de datos"},
+}
+
+// exitCodeOrder fija el orden en que se documentan los codigos en el uso.
+var exitCodeOrder = []errorKind{
+	kindUnexpected, kindUsage, kindSpooled, kindConfig, kindEnv, kindNoEthernet,
+	kindNoIP, kindPreflight, kindDBConnection, kindDBInsert, kindDBVerification, kindDBMigration,
+	kindDBQuery,
+}
+
+const exitOK = 0
+
+type appError struct {
+	kind errorKind
+	err  error
+}
+
+func (e *appError) Error() string {
+	msg := errorKinds[e.kind].message
+	if e.err == nil {
+		return msg
+	}
+	if e.kind == kindUsage {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%s: %v", msg, e.err)
+}
+
+func (e *appError) Unwrap() error {
+	return e.err
+}
+
+func newError(kind errorKind, err error) error {
+	return &appError{kind: kind, err: err}
+}
+
+func errorf(kind errorKind, format string, args ...interface{}) error {
+	return &appError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return errorf(kindUsage, format, args...)
+}
+
+// errCaptureSpooled indica que la captura no se perdio pero quedo en el
+// spool esperando un sync.
+var errCaptureSpooled = &appError{kind: kindSpooled}
+
+// saveError clasifica un error de EquipoStore.Create segun haya fallado la
+// escritura o la verificacion posterior.
+func saveError(err error) error {
+	if errors.Is(err, repository.ErrVerificacion) {
+		return newError(kindDBVerification, err)
+	}
+	return newError(kindDBInsert, err)
+}
+
+func errorKindOf(err error) errorKind {
+	var appErr *appError
+	if errors.As(err, &appErr) {
+		return appErr.kind
+	}
+	return kindUnexpected
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	return errorKinds[errorKindOf(err)].code
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"relevamiento/repository"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"sin error", nil, exitOK},
+		{"error sin tipo", errors.New("boom"), 1},
+		{"uso", usageErrorf("comando desconocido: x"), 2},
+		{"spool", errCaptureSpooled, 3},
+		{"entorno", errorf(kindEnv, "archivo .env no encontrado"), 11},
+		{"envuelto", fmt.Errorf("capture: %w", newError(kindNoEthernet, nil)), 20},
+		{"conexion", newError(kindDBConnection, errors.New("dial tcp: refused")), 30},
+		{"insercion", saveError(errors.New("duplicado")), 31},
+		{"verificacion", saveError(fmt.Errorf("id 7: %w", repository.ErrVerificacion)), 32},
+		{"consulta", newError(kindDBQuery, errors.New("timeout")), 34},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, se esperaba %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCodesDocumented(t *testing.T) {
+	if len(exitCodeOrder) != len(errorKinds) {
+		t.Fatalf("exitCodeOrder tiene %d tipos, errorKinds %d", len(exitCodeOrder), len(errorKinds))
+	}
+	seen := map[int]errorKind{exitOK: -1}
+	for _, kind := range exitCodeOrder {
+		code := errorKinds[kind].code
+		if prev, ok := seen[code]; ok {
+			t.Errorf("codigo %d repetido en %v y %v", code, prev, kind)
+		}
+		seen[code] = kind
+	}
+}
+
+func TestDBConnectionErrorNotDoubled(t *testing.T) {
+	t.Setenv("DB_DRIVER", repository.DriverMySQL)
+	t.Setenv("DB_USER", "u")
+	t.Setenv("DB_PASS", "p")
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", "1")
+	t.Setenv("DB_NAME", "relevamiento")
+
+	_, err := initStore()
+	if err == nil {
+		t.Skip("hay algo escuchando en 127.0.0.1:1")
+	}
+
+	msg := newError(kindDBConnection, err).Error()
+	if n := strings.Count(strings.ToLower(msg), "no se pudo conectar a la base de datos"); n != 1 {
+		t.Errorf("mensaje = %q, el prefijo aparece %d veces", msg, n)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	prevOptions, prevStderr := options, os.Stderr
+	t.Cleanup(func() { options, os.Stderr = prevOptions, prevStderr })
+
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stderr = devNull
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"capture", "--help"}, exitOK},
+		{[]string{"--help"}, exitOK},
+		{[]string{"capture", "--bogus"}, 2},
+		{[]string{"no-existe"}, 2},
+		{[]string{"--unattended"}, 2},
+		{[]string{"--output", "xml", "version"}, 2},
+		{[]string{"capture"}, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			options = cliOptions{output: outputText}
+			if got := run(tt.args); got != tt.want {
+				t.Errorf("run(%q) = %d, se esperaba %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarning:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	}
+	return "UNKNOWN"
+}
+
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warning", "warn":
+		return LevelWarning, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("nivel de log invalido: %s (use debug, info, warning o error)", s)
+}
+
+// Rotation define cuando se rota el archivo de log. Un valor 0 desactiva
+// el limite correspondiente.
+type Rotation struct {
+	MaxSizeMB  int
+	MaxAgeDays int
+	MaxBackups int
+}
+
+const backupTimeFormat = "20060102-150405.000000"
+
+// Logger escribe cada evento una sola vez, en texto o JSON lines, con los
+// campos de contexto fijados con SetField (equipo, MAC, captura).
+type Logger struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	out      io.Writer
+	size     int64
+	level    Level
+	json     bool
+	rotation Rotation
+	fields   map[string]string
+	now      func() time.Time
+}
+
+// Open abre (o crea) el archivo de log. Si no se puede abrir, los eventos
+// van a stderr y se devuelve el error para informarlo.
+func Open(path string, rotation Rotation) (*Logger, error) {
+	l := &Logger{
+		path:     path,
+		out:      os.Stderr,
+		level:    LevelInfo,
+		rotation: rotation,
+		fields:   map[string]string{},
+		now:      time.Now,
+	}
+
+	if err := l.openFile(); err != nil {
+		return l, err
+	}
+	l.rotateIfStale()
+
+	return l, nil
+}
+
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+func (l *Logger) SetJSON(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.json = enabled
+}
+
+func (l *Logger) SetRotation(rotation Rotation) {
+	l.mu.Lock()
+	l.rotation = rotation
+	l.mu.Unlock()
+	l.rotateIfStale()
+}
+
+// SetField agrega un campo que acompana a todos los eventos siguientes. Un
+// valor vacio lo elimina.
+func (l *Logger) SetField(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if value == "" {
+		delete(l.fields, key)
+		return
+	}
+	l.fields[key] = value
+}
+
+func (l *Logger) Debug(msg string) {
+	l.Log(LevelDebug, msg, nil)
+}
+
+func (l *Logger) Info(msg string) {
+	l.Log(LevelInfo, msg, nil)
+}
+
+func (l *Logger) Warning(msg string) {
+	l.Log(LevelWarning, msg, nil)
+}
+
+func (l *Logger) Error(msg string, err error) {
+	l.Log(LevelError, msg, err)
+}
+
+func (l *Logger) Log(level Level, msg string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level < l.level {
+		return
+	}
+
+	line := l.format(level, msg, err)
+	l.rotateIfTooBig(int64(len(line)))
+
+	n, _ := io.WriteString(l.out, line)
+	l.size += int64(n)
+}
+
+// Writer adapta el Logger a io.Writer para redirigir el paquete log
+// estandar sin duplicar lineas.
+func (l *Logger) Writer(level Level) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		l.Log(level, strings.TrimRight(string(p), "\n"), nil)
+		return len(p), nil
+	})
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	l.out = os.Stderr
+	return err
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func (l *Logger) format(level Level, msg string, err error) string {
+	timestamp := l.now()
+
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if l.json {
+		event := map[string]string{
+			"time":  timestamp.Format(time.RFC3339),
+			"level": strings.ToLower(level.String()),
+			"msg":   msg,
+		}
+		if err != nil {
+			event["error"] = err.Error()
+		}
+		for _, k := range keys {
+			event[k] = l.fields[k]
+		}
+		data, _ := json.Marshal(event)
+		return string(data) + "\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s - %s", level, timestamp.Format("2006-01-02 15:04:05"), msg)
+	if err != nil {
+		fmt.Fprintf(&b, " - %v", err)
+	}
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%q", k, l.fields[k])
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (l *Logger) openFile() error {
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("no se pudo crear archivo de log: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err == nil {
+		l.size = info.Size()
+	}
+
+	l.file = file
+	l.out = file
+	return nil
+}
+
+func (l *Logger) rotateIfTooBig(next int64) {
+	if l.file == nil || l.rotation.MaxSizeMB <= 0 {
+		return
+	}
+	if l.size+next <= int64(l.rotation.MaxSizeMB)*1024*1024 {
+		return
+	}
+	l.rotate()
+}
+
+// rotateIfStale rota el archivo actual si no se escribe desde hace mas de
+// MaxAgeDays y elimina los respaldos vencidos.
+func (l *Logger) rotateIfStale() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil || l.rotation.MaxAgeDays <= 0 {
+		l.pruneBackups()
+		return
+	}
+
+	info, err := l.file.Stat()
+	if err == nil && info.Size() > 0 && l.now().Sub(info.ModTime()) > l.maxAge() {
+		l.rotate()
+		return
+	}
+	l.pruneBackups()
+}
+
+func (l *Logger) rotate() {
+	l.file.Close()
+	l.file = nil
+	l.out = os.Stderr
+
+	backup := fmt.Sprintf("%s.%s", l.path, l.now().Format(backupTimeFormat))
+	if err := os.Rename(l.path, backup); err != nil {
+		fmt.Fprintf(os.Stderr, "No se pudo rotar el log: %v\n", err)
+	}
+
+	if err := l.openFile(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	l.pruneBackups()
+}
+
+// pruneBackups elimina los respaldos que exceden MaxBackups o MaxAgeDays.
+// Los archivos con el mismo prefijo que no son respaldos (por ejemplo una
+// copia manual) no se tocan ni cuentan.
+func (l *Logger) pruneBackups() {
+	backups, _ := filepath.Glob(l.path + ".*")
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	kept := 0
+	for _, backup := range backups {
+		stamp := strings.TrimPrefix(backup, l.path+".")
+		created, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		tooMany := l.rotation.MaxBackups > 0 && kept >= l.rotation.MaxBackups
+		tooOld := l.rotation.MaxAgeDays > 0 && l.now().Sub(created) > l.maxAge()
+		if tooMany || tooOld {
+			os.Remove(backup)
+			continue
+		}
+		kept++
+	}
+}
+
+func (l *Logger) maxAge() time.Duration {
+	return time.Duration(l.rotation.MaxAgeDays) * 24 * time.Hour
+}
@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+
+func openTestLogger(t *testing.T, rotation Rotation) (*Logger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "error.log")
+	l, err := Open(path, rotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	l.now = func() time.Time { return testNow }
+	return l, path
+}
+
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, m := range matches {
+		names = append(names, filepath.Base(m))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeBackup(t *testing.T, path string, created time.Time) string {
+	t.Helper()
+	name := path + "." + created.Format(backupTimeFormat)
+	if err := os.WriteFile(name, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Base(name)
+}
+
+func TestRotateBySize(t *testing.T) {
+	l, path := openTestLogger(t, Rotation{MaxSizeMB: 1})
+
+	big := strings.Repeat("x", 600*1024)
+	l.Info(big)
+	l.Info(big)
+
+	got := backups(t, path)
+	want := []string{"error.log." + testNow.Format(backupTimeFormat)}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("respaldos = %v, se esperaba %v", got, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 1024*1024 || info.Size() < 600*1024 {
+		t.Errorf("el log nuevo tiene %d bytes, se esperaba solo la segunda linea", info.Size())
+	}
+}
+
+func TestRotateStaleOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "error.log")
+	if err := os.WriteFile(path, []byte("viejo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Open(path, Rotation{MaxAgeDays: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if got := backups(t, path); len(got) != 1 {
+		t.Fatalf("respaldos = %v, se esperaba uno con el log viejo", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("el log actual debia quedar vacio: %v, %v", info, err)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	l, path := openTestLogger(t, Rotation{})
+
+	var kept []string
+	for i := 0; i < 4; i++ {
+		name := writeBackup(t, path, testNow.Add(-time.Duration(i)*time.Hour))
+		if i < 2 {
+			kept = append(kept, name)
+		}
+	}
+	writeBackup(t, path, testNow.Add(-45*24*time.Hour))
+	for _, manual := range []string{"error.log.bak", "error.log.zz"} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(path), manual), []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		kept = append(kept, manual)
+	}
+
+	l.SetRotation(Rotation{MaxBackups: 2, MaxAgeDays: 30})
+
+	sort.Strings(kept)
+	if got := backups(t, path); strings.Join(got, ",") != strings.Join(kept, ",") {
+		t.Errorf("respaldos = %v, se esperaba %v", got, kept)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	l, path := openTestLogger(t, Rotation{})
+	l.SetJSON(true)
+	l.SetField("mac", "AA-BB-CC-DD-EE-FF")
+	l.SetField("capture_id", "c0ffee")
+	l.Debug("no se escribe")
+	l.Error("fallo la captura", errors.New("sin red"))
+	l.SetField("capture_id", "")
+	l.Warning("segunda")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("se escribieron %d lineas, se esperaban 2:\n%s", len(lines), data)
+	}
+
+	var first, second map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"time":       testNow.Format(time.RFC3339),
+		"level":      "error",
+		"msg":        "fallo la captura",
+		"error":      "sin red",
+		"mac":        "AA-BB-CC-DD-EE-FF",
+		"capture_id": "c0ffee",
+	}
+	for k, v := range want {
+		if first[k] != v {
+			t.Errorf("%s = %q, se esperaba %q", k, first[k], v)
+		}
+	}
+	if _, ok := second["capture_id"]; ok || second["level"] != "warning" {
+		t.Errorf("segunda linea = %v, se esperaba warning sin capture_id", second)
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	l, path := openTestLogger(t, Rotation{})
+	l.SetField("mac", "AA-BB-CC-DD-EE-FF")
+	l.Error("fallo", errors.New("sin red"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[ERROR] 2024-03-01 10:00:00 - fallo - sin red mac="AA-BB-CC-DD-EE-FF"` + "\n"
+	if string(data) != want {
+		t.Errorf("linea = %q, se esperaba %q", data, want)
+	}
+}
@@ -1,53 +1,186 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
-	"net"
 	"os"
 	"path/filepath"
 	"relevamiento/core"
+	"relevamiento/logging"
 	"relevamiento/repository"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 )
 
-var logFile *os.File
+var logger *logging.Logger
 
 func main() {
-	defer handlePanic()
-	defer waitForExit()
-
-	initLogging()
-	defer closeLogging()
-
-	logInfo("Iniciando relevamiento...")
+	os.Exit(run(os.Args[1:]))
+}
 
+func printBanner() {
 	fmt.Println(strings.Repeat("=", 60))
 	fmt.Println("       RELEVAMIENTO DE EQUIPOS")
 	fmt.Println(strings.Repeat("=", 60))
+}
 
+// loadEnvironment valida el entorno y carga el .env. Solo lo exigen los
+// comandos que relevan el equipo o usan la base de datos.
+func loadEnvironment() error {
 	if err := validateEnvironment(); err != nil {
 		logError("Error en validacion inicial", err)
-		log.Fatalf("[ERROR] %v", err)
+		return newError(kindEnv, err)
 	}
 
 	if err := godotenv.Load(); err != nil {
 		logError("Archivo .env no encontrado", err)
-		log.Fatal("Error: archivo .env no encontrado")
+		return errorf(kindEnv, "archivo .env no encontrado")
+	}
+
+	return applyEnvironment()
+}
+
+// loadOptionalEnvironment carga el .env si existe, para que los comandos de
+// diagnostico usen la misma configuracion (reglas, OUI, backend) que
+// capture. Sin .env se usan los valores predeterminados.
+func loadOptionalEnvironment() error {
+	if err := godotenv.Load(); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logError("Archivo .env invalido", err)
+			return errorf(kindEnv, "archivo .env invalido: %v", err)
+		}
+		logDebug("Sin archivo .env, se usa la configuracion predeterminada")
+	}
+
+	return applyEnvironment()
+}
+
+// applyEnvironment aplica la configuracion leida del .env.
+func applyEnvironment() error {
+	loadOUITable()
+
+	if err := core.SetCollectorBackend(os.Getenv("COLLECTOR_BACKEND")); err != nil {
+		logError("COLLECTOR_BACKEND invalido", err)
+		return newError(kindEnv, err)
+	}
+
+	if err := loadAdapterRules(""); err != nil {
+		return err
+	}
+
+	if err := loadNetworkRanges(); err != nil {
+		return err
+	}
+
+	if err := loadLocationMap(); err != nil {
+		return err
+	}
+
+	return applyLogOptions()
+}
+
+// loadAdapterRules carga las reglas de clasificacion de adaptadores desde
+// path o, si esta vacio, desde ADAPTER_RULES_FILE. Sin archivo se usan las
+// reglas predeterminadas.
+func loadAdapterRules(path string) error {
+	if path == "" {
+		path = os.Getenv("ADAPTER_RULES_FILE")
+	}
+	if path == "" {
+		return nil
+	}
+	if err := core.LoadAdapterRules(path); err != nil {
+		logError("Error cargando reglas de adaptadores", err)
+		return newError(kindEnv, err)
+	}
+	logInfo(fmt.Sprintf("Reglas de adaptadores cargadas: %s", path))
+	return nil
+}
+
+// loadNetworkRanges carga los rangos de red esperados desde
+// NETWORK_RANGES_FILE o NETWORK_RANGES. NETWORK_PREFIX se sigue aceptando y
+// se convierte al CIDR equivalente.
+func loadNetworkRanges() error {
+	var (
+		ranges []core.NetworkRange
+		source string
+		err    error
+	)
+
+	switch {
+	case os.Getenv("NETWORK_RANGES_FILE") != "":
+		source = os.Getenv("NETWORK_RANGES_FILE")
+		ranges, err = core.LoadNetworkRanges(source)
+	case os.Getenv("NETWORK_RANGES") != "":
+		source = "NETWORK_RANGES"
+		ranges, err = core.ParseNetworkRanges(os.Getenv("NETWORK_RANGES"))
+	case os.Getenv("NETWORK_PREFIX") != "":
+		source = "NETWORK_PREFIX"
+		var r core.NetworkRange
+		r, err = core.RangeFromPrefix(os.Getenv("NETWORK_PREFIX"))
+		ranges = []core.NetworkRange{r}
+		if err == nil {
+			logWarning(fmt.Sprintf("NETWORK_PREFIX esta obsoleto, use NETWORK_RANGES=%s", r.CIDR))
+		}
+	default:
+		return nil
+	}
+
+	if err != nil {
+		logError("Error cargando rangos de red", err)
+		return newError(kindEnv, err)
+	}
+
+	core.SetNetworkRanges(ranges)
+	labels := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		labels = append(labels, r.Label())
 	}
+	logInfo(fmt.Sprintf("Rangos de red (%s): %s", source, strings.Join(labels, ", ")))
+	return nil
+}
 
-	showMenu()
+// loadLocationMap carga el mapa opcional de subred a ubicacion indicado en
+// LOCATION_MAP_FILE.
+func loadLocationMap() error {
+	path := os.Getenv("LOCATION_MAP_FILE")
+	if path == "" {
+		return nil
+	}
+	if err := core.LoadLocationMap(path); err != nil {
+		logError("Error cargando mapa de ubicaciones", err)
+		return newError(kindEnv, err)
+	}
+	logInfo(fmt.Sprintf("Mapa de ubicaciones cargado: %s", path))
+	return nil
 }
 
-func showMenu() {
+// loadOUITable carga el registro OUI completo del IEEE si se indico en
+// OUI_FILE. Si no, o si falla, se sigue con la tabla parcial embebida, que
+// deja sin fabricante a las MAC que no conoce.
+func loadOUITable() {
+	path := os.Getenv("OUI_FILE")
+	if path == "" {
+		logInfo("Sin OUI_FILE: se usa la tabla OUI parcial embebida")
+		return
+	}
+	if err := core.LoadOUIFile(path); err != nil {
+		logError("No se pudo cargar OUI_FILE, se usa la tabla OUI parcial embebida", err)
+		return
+	}
+	logInfo(fmt.Sprintf("Tabla OUI cargada: %s", path))
+}
+
+func showMenu() error {
 	config, _ := core.LoadLocationConfig()
 
 	fmt.Println("\n" + strings.Repeat("=", 60))
@@ -58,93 +191,216 @@ func showMenu() {
 		fmt.Println("Sin configuracion guardada")
 	}
 
+	if pending, err := newSpool().Pending(); err == nil && len(pending) > 0 {
+		fmt.Printf("Capturas pendientes de sincronizar: %d\n", len(pending))
+	}
+
 	fmt.Println("\n[1] Captura rapida")
 	fmt.Println("[2] Configurar ubicacion")
+	fmt.Println("[3] Sincronizar capturas pendientes")
+	fmt.Println("[4] Reporte de dominio por piso")
 
-	var opcion string
 	for {
 		fmt.Print("\nOpcion: ")
-		fmt.Scanln(&opcion)
-		
+		opcion, ok := core.ReadConsoleLine(0)
+		if !ok {
+			return errorf(kindUsage, "entrada de consola cerrada")
+		}
+
 		if opcion == "1" {
+			if config == nil && core.HasLocationMap() {
+				return executeCapture("", "")
+			}
 			if config == nil {
 				fmt.Println("[X] Debe configurar primero (opcion 2)")
 				continue
 			}
-			executeCapture(config.Piso, config.Oficina)
-			return
+			return executeCapture(config.Piso, config.Oficina)
 		} else if opcion == "2" {
-			configureOnly()
-			return
+			return configureOnly()
+		} else if opcion == "3" {
+			return syncSpool()
+		} else if opcion == "4" {
+			return reportDominio()
 		} else {
 			fmt.Println("[X] Opcion invalida")
 		}
 	}
 }
 
-func configureOnly() {
-	reader := bufio.NewReader(os.Stdin)
-
+func configureOnly() error {
 	fmt.Println("\n" + strings.Repeat("-", 60))
 	fmt.Println("       CONFIGURAR UBICACION")
 	fmt.Println(strings.Repeat("-", 60))
 
 	fmt.Print("\nPISO (presione Enter para usar '0'): ")
-	piso, _ := reader.ReadString('\n')
-	piso = strings.TrimSpace(piso)
+	piso, _ := core.ReadConsoleLine(0)
 	if piso == "" {
 		piso = "0"
 	}
 
 	fmt.Print("OFICINA: ")
-	oficina, _ := reader.ReadString('\n')
-	oficina = strings.TrimSpace(oficina)
-	
+	oficina, _ := core.ReadConsoleLine(0)
+
+	return saveLocation(piso, oficina)
+}
+
+func saveLocation(piso, oficina string) error {
 	if oficina == "" {
 		logError("Oficina vacia", nil)
-		log.Fatal("[ERROR] La oficina es obligatoria")
+		return errorf(kindConfig, "la oficina es obligatoria")
 	}
 
 	if err := core.SaveLocationConfig(piso, oficina); err != nil {
 		logError("No se pudo guardar configuracion", err)
-		fmt.Printf("[!] No se pudo guardar: %v\n", err)
-		return
+		return errorf(kindConfig, "no se pudo guardar: %v", err)
 	}
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("[OK] CONFIGURACION GUARDADA")
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("\nPiso:    %s\n", piso)
-	fmt.Printf("Oficina: %s\n", oficina)
-	fmt.Println(strings.Repeat("=", 60))
-	
 	logInfo(fmt.Sprintf("Configuracion guardada: Piso %s - %s", piso, oficina))
+
+	config := &core.LocationConfig{Piso: piso, Oficina: oficina}
+	return emit(configRecord(config, core.GetConfigFilePath()), func() {
+		fmt.Println("\n" + strings.Repeat("=", 60))
+		fmt.Println("[OK] CONFIGURACION GUARDADA")
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Printf("\nPiso:    %s\n", piso)
+		fmt.Printf("Oficina: %s\n", oficina)
+		fmt.Println(strings.Repeat("=", 60))
+	})
 }
 
-func executeCapture(piso, oficina string) {
-	db, err := initDB()
+func executeCapture(piso, oficina string) error {
+	captureID := newCaptureID()
+	setLogField("capture_id", captureID)
+
+	equipoInfo, err := collectEquipoInfo(piso, oficina, !options.skipPreflight)
+	if err != nil {
+		return err
+	}
+	equipoInfo.CaptureID = captureID
+	if equipoInfo.Oficina == "" {
+		logError("Oficina vacia", nil)
+		return errorf(kindConfig, "la subred no tiene oficina asignada: use 'configure' o --oficina")
+	}
+
+	store, err := initStore()
 	if err != nil {
 		logError("Error de conexion a DB", err)
-		log.Fatalf("Error de conexion a DB: %v", err)
+		return spoolCapture(equipoInfo, newError(kindDBConnection, err))
 	}
-	defer db.Close()
+	defer store.Close()
 
-	computerName := getEnv("COMPUTERNAME", "Desconocido")
+	fmt.Println("\n>> Guardando...")
+	result, err := store.Create(equipoInfo)
+	if err != nil || !result.Success {
+		logError("Error al guardar en DB", err)
+		if err == nil {
+			err = fmt.Errorf("%s", result.ErrorMessage)
+		}
+		return spoolCapture(equipoInfo, saveError(err))
+	}
+
+	if result.Created {
+		logInfo(fmt.Sprintf("Registro exitoso - equipo nuevo ID: %d", result.InsertedID))
+	} else {
+		logInfo(fmt.Sprintf("Registro exitoso - equipo existente ID: %d actualizado", result.InsertedID))
+	}
+
+	return emit(captureRecord(equipoInfo, result), func() {
+		printSuccess(result)
+	})
+}
+
+// executeDryRun releva el equipo igual que executeCapture pero solo muestra
+// el registro, sin conectarse a la base ni usar el spool.
+func executeDryRun(piso, oficina string) error {
+	captureID := newCaptureID()
+	setLogField("capture_id", captureID)
+
+	equipoInfo, err := collectEquipoInfo(piso, oficina, false)
+	if err != nil {
+		return err
+	}
+	equipoInfo.CaptureID = captureID
+
+	logInfo("Dry-run: registro no guardado")
+
+	return emit(equipoRecord(equipoInfo), func() {
+		printEquipoTable(equipoInfo)
+	})
+}
+
+// collectEquipoInfo detecta MAC, IP, dominio y hardware y arma el registro
+// a guardar. Con preflight muestra el diagnostico de red del adaptador
+// elegido y, si encuentra fallas, corta la captura salvo --ignore-preflight.
+func collectEquipoInfo(piso, oficina string, preflight bool) (repository.EquipoInfo, error) {
+	computerName := core.ComputerName()
+	if computerName == "" {
+		computerName = "Desconocido"
+	}
+	setLogField("computer", computerName)
 	logInfo(fmt.Sprintf("Computer Name: %s", computerName))
+
+	if len(core.ActiveNetworkRanges()) == 0 {
+		logError("Rangos de red no configurados", nil)
+		return repository.EquipoInfo{}, errorf(kindEnv, "configure NETWORK_RANGES o NETWORK_RANGES_FILE en .env")
+	}
 	
-	macAddress, err := core.GetEthernetMacWithConfirmation()
-	if err != nil || macAddress == "No disponible" {
+	selection, err := core.SelectEthernetAdapter(core.SelectionOptions{
+		Interactive: !options.unattended,
+		Timeout:     time.Duration(getEnvInt("ADAPTER_PROMPT_TIMEOUT", 20)) * time.Second,
+	})
+	adapter := selection.Adapter
+	macAddress := core.NormalizeMac(adapter.MacAddress)
+	if err != nil || macAddress == "" {
 		logError("No se pudo obtener MAC", err)
-		log.Fatal("[ERROR] No se pudo obtener MAC de Ethernet")
+		if err == nil {
+			err = fmt.Errorf("no se pudo obtener MAC de Ethernet")
+		}
+		return repository.EquipoInfo{}, newError(kindNoEthernet, err)
+	}
+	setLogField("mac", macAddress)
+	logInfo(fmt.Sprintf("MAC detectada: %s (%s) - Adaptador: %s - Seleccion: %s", macAddress, valueOrDash(adapter.Vendor), adapter.Name, selection.Reason))
+	if adapter.RandomMac {
+		logWarning(fmt.Sprintf("La MAC %s parece aleatoria o privada", macAddress))
+	}
+	logInfo(fmt.Sprintf("Adaptador: %s - Estado: %s - Gateway: %s - IPv4: %s - IPv6: %s",
+		adapter.Name, adapter.Status, valueOrDash(adapter.Gateway),
+		valueOrDash(strings.Join(adapter.IPv4, ", ")), valueOrDash(strings.Join(adapter.IPv6, ", "))))
+
+	if preflight {
+		checks := runPreflight(&adapter)
+		printPreflight(checks)
+		if core.PreflightFailed(checks) {
+			if !options.ignorePreflight {
+				logError("El diagnostico de red encontro fallas", nil)
+				return repository.EquipoInfo{}, errorf(kindPreflight, "revise los puntos marcados con [X] o use --ignore-preflight")
+			}
+			logWarning("El diagnostico de red encontro fallas: se continua por --ignore-preflight")
+		}
 	}
-	logInfo(fmt.Sprintf("MAC detectada: %s", macAddress))
 
-	ipAddress := getIPAddress()
-	if ipAddress == "No disponible" {
+	address, err := getIPAddress(adapter)
+	if err != nil {
+		return repository.EquipoInfo{}, err
+	}
+	if address.IP == "" {
 		logError("No se pudo obtener IP", nil)
-		log.Fatal("[ERROR] No se pudo obtener IP del equipo")
+		return repository.EquipoInfo{}, errorf(kindNoIP, "no se pudo obtener IP del equipo")
+	}
+	logInfo(fmt.Sprintf("IP detectada: %s - Rango: %s", address.IP, address.RangeLabel()))
+	rangoRed := ""
+	if address.Matched {
+		rangoRed = address.Range.Label()
+	}
+
+	facts := core.GetNetworkFacts(adapter)
+	logNetworkFacts(adapter, facts)
+
+	location, edificio := resolveLocation(address.IP, core.LocationConfig{Piso: piso, Oficina: oficina})
+	if location.Piso == "" {
+		location.Piso = "0"
 	}
-	logInfo(fmt.Sprintf("IP detectada: %s", ipAddress))
 
 	domainInfo := core.GetDomainInfo()
 	if !domainInfo.EnDominio {
@@ -154,103 +410,512 @@ func executeCapture(piso, oficina string) {
 		logInfo(fmt.Sprintf("Dominio: %s", domainInfo.NombreDominio))
 	}
 
+	fmt.Println("\n>> Relevando hardware...")
+	sysInfo, sysReport := core.GetSystemInfoReport()
+	for _, u := range sysReport.Unresolved {
+		logWarning(fmt.Sprintf("systeminfo: no se resolvio %s (%s)", u.Field, u.Reason))
+	}
+	serialNumber, biosVersion := core.GetBIOSInfo()
+	serialNumber = core.NormalizeSerialNumber(serialNumber)
+	logInfo(fmt.Sprintf("Hardware: %s %s - Serie: %s - RAM: %s", sysInfo.Manufacturer, sysInfo.Model, serialNumber, sysInfo.MemoryRAM))
+
 	equipoInfo := repository.EquipoInfo{
-		FechaRelevamiento: time.Now().Format("2006-01-02 15:04:05"),
-		ComputerName:      computerName,
-		NombreAnterior:    computerName,
-		MacAddress:        macAddress,
-		IPAddress:         ipAddress,
-		Piso:              piso,
-		Oficina:           oficina,
+		FechaRelevamiento:  time.Now().Format("2006-01-02 15:04:05"),
+		ComputerName:       computerName,
+		NombreAnterior:     computerName,
+		MacAddress:         macAddress,
+		MacFabricante:      adapter.Vendor,
+		AdaptadorPrincipal: adapter.Name,
+		MotivoSeleccion:    selection.Reason,
+		IPAddress:          address.IP,
+		RangoRed:           rangoRed,
+		Edificio:           edificio,
+		Piso:               location.Piso,
+		Oficina:            location.Oficina,
+		SerialNumber:       serialNumber,
+		SistemaOperativo:   sysInfo.OS,
+		VersionSO:          sysInfo.Version,
+		Arquitectura:       sysInfo.Architecture,
+		MemoriaRAM:         sysInfo.MemoryRAM,
+		MemoriaRAMMB:       core.ParseMemoryMB(sysInfo.MemoryRAM),
+		Procesador:         sysInfo.Processor,
+		UsuarioActual:      sysInfo.CurrentUser,
+		Fabricante:         sysInfo.Manufacturer,
+		Modelo:             sysInfo.Model,
+		BIOSVersion:        biosVersion,
+		EnDominio:          domainInfo.EnDominio,
+		NombreDominio:      domainInfo.NombreDominio,
+		EsMecLocal:         domainInfo.EsMecLocal,
+		Gateway:            facts.Gateway,
+		ServidoresDNS:      strings.Join(facts.DNSServers, ","),
+		DHCPHabilitado:     facts.DHCPEnabled,
+		ServidorDHCP:       facts.DHCPServer,
+		LeaseObtenido:      facts.LeaseObtained,
+		LeaseExpira:        facts.LeaseExpires,
+		VelocidadMbps:      facts.SpeedMbps,
+		Duplex:             facts.Duplex,
+		Adaptadores:        collectAdaptadores(adapter),
 	}
 
-	fmt.Println("\n>> Guardando...")
-	result, err := repository.CreateEquiposRepository(db, equipoInfo)
-	if err != nil || !result.Success {
-		logError("Error al guardar en DB", err)
-		log.Fatalf("[ERROR] %s", result.ErrorMessage)
+	return equipoInfo, nil
+}
+
+// logNetworkFacts registra la configuracion de red del adaptador principal y
+// advierte lo que suele indicar un problema: enlace por debajo de 1 Gb/s,
+// half duplex o IP fija.
+func logNetworkFacts(adapter core.NetworkAdapter, facts core.NetworkFacts) {
+	dhcp := "desconocido"
+	switch {
+	case facts.DHCPEnabled == nil:
+	case !*facts.DHCPEnabled:
+		dhcp = "NO (IP fija)"
+	default:
+		dhcp = fmt.Sprintf("SI - Servidor: %s - Lease: %s a %s",
+			valueOrDash(facts.DHCPServer), valueOrDash(facts.LeaseObtained), valueOrDash(facts.LeaseExpires))
+	}
+	logInfo(fmt.Sprintf("Red: Gateway %s - DNS %s - DHCP %s - Enlace %s %s",
+		valueOrDash(facts.Gateway), valueOrDash(strings.Join(facts.DNSServers, ", ")), dhcp,
+		valueOrDash(adapter.Speed), valueOrDash(facts.Duplex)))
+
+	if facts.SpeedMbps > 0 && facts.SpeedMbps < 1000 {
+		fmt.Printf("\n[!] ADVERTENCIA: El enlace negocio %s\n", adapter.Speed)
+		logWarning(fmt.Sprintf("Enlace de %s en %s", adapter.Speed, adapter.Name))
+	}
+	if facts.Duplex == core.DuplexHalf {
+		fmt.Println("\n[!] ADVERTENCIA: El enlace esta en half duplex")
+		logWarning(fmt.Sprintf("Enlace half duplex en %s", adapter.Name))
+	}
+	if facts.DHCPEnabled != nil && !*facts.DHCPEnabled {
+		logWarning(fmt.Sprintf("El adaptador %s no usa DHCP", adapter.Name))
+	}
+}
+
+// resolveLocation compara la ubicacion configurada con la del mapa de
+// subredes. La subred solo completa los datos que faltan; si contradice lo
+// configurado se advierte y, salvo en modo desatendido, se pregunta si usarla.
+// Devuelve tambien el edificio de la subred, salvo que se mantenga una
+// ubicacion configurada que la contradice.
+func resolveLocation(ip string, config core.LocationConfig) (core.LocationConfig, string) {
+	mapping, ok := core.LookupLocation(ip)
+	if !ok {
+		if core.HasLocationMap() {
+			logWarning(fmt.Sprintf("La IP %s no figura en el mapa de ubicaciones", ip))
+		}
+		return config, ""
+	}
+	logInfo(fmt.Sprintf("Ubicacion segun la subred: %s", mapping))
+
+	if config.Piso == "" && config.Oficina == "" {
+		fmt.Printf("\n>> Ubicacion segun la subred: %s\n", mapping)
+		return mapping.Fill(config), mapping.Edificio
+	}
+
+	conflicts := mapping.Conflicts(config)
+	if len(conflicts) == 0 {
+		return mapping.Fill(config), mapping.Edificio
+	}
+
+	fmt.Printf("\n[!] ADVERTENCIA: La ubicacion configurada no coincide con la subred %s\n", mapping)
+	for _, c := range conflicts {
+		fmt.Printf("    - %s\n", c)
+	}
+	logWarning(fmt.Sprintf("Ubicacion configurada (Piso %s - %s) contradice la subred %s: %s",
+		config.Piso, config.Oficina, mapping, strings.Join(conflicts, ", ")))
+
+	if !options.unattended {
+		timeout := time.Duration(getEnvInt("LOCATION_PROMPT_TIMEOUT", 20)) * time.Second
+		fmt.Printf("Usar la ubicacion de la subred? [s/N] (%d s): ", int(timeout.Seconds()))
+		answer, _ := core.ReadConsoleLine(timeout)
+		if strings.EqualFold(answer, "s") {
+			logInfo("Se usa la ubicacion de la subred")
+			return mapping.Apply(config), mapping.Edificio
+		}
+	}
+
+	logInfo("Se mantiene la ubicacion configurada")
+	return mapping.Fill(config), ""
+}
+
+// runPreflight arma la verificacion de red con la configuracion del .env
+// para el adaptador elegido (nil para la eleccion automatica). Las
+// verificaciones de la base solo aplican con MySQL.
+func runPreflight(adapter *core.NetworkAdapter) []core.PreflightCheck {
+	cfg := core.PreflightConfig{
+		Timeout: 5 * time.Second,
+		Adapter: adapter,
+	}
+	if getEnv("DB_DRIVER", repository.DriverMySQL) == repository.DriverMySQL {
+		cfg.DBHost = os.Getenv("DB_HOST")
+		cfg.DBPort = getEnv("DB_PORT", "3306")
+	}
+
+	checks := core.RunPreflight(cfg)
+	for _, c := range checks {
+		msg := fmt.Sprintf("Preflight %s: %s - %s", c.Name, c.Status, c.Detail)
+		switch c.Status {
+		case core.CheckFail:
+			logError(msg, nil)
+		case core.CheckWarn:
+			logWarning(msg)
+		default:
+			logInfo(msg)
+		}
+	}
+	return checks
+}
+
+func printPreflight(checks []core.PreflightCheck) {
+	fmt.Println("\n" + strings.Repeat("-", 60))
+	fmt.Println("       DIAGNOSTICO DE RED")
+	fmt.Println(strings.Repeat("-", 60))
+
+	for _, c := range checks {
+		mark := "[OK]"
+		switch c.Status {
+		case core.CheckWarn:
+			mark = "[!] "
+		case core.CheckFail:
+			mark = "[X] "
+		}
+		detail := c.Detail
+		if c.Duration > 0 {
+			detail = fmt.Sprintf("%s (%d ms)", detail, c.Duration.Milliseconds())
+		}
+		fmt.Printf("%s %-24s %s\n", mark, c.Name, detail)
 	}
 
-	logInfo(fmt.Sprintf("Registro exitoso - ID: %d", result.InsertedID))
-	printSuccess(result)
+	fmt.Println(strings.Repeat("-", 60))
+}
+
+// collectAdaptadores guarda todos los adaptadores del equipo y marca como
+// principal el que se uso para la captura.
+func collectAdaptadores(principal core.NetworkAdapter) []repository.AdapterInfo {
+	adapters := core.GetAllNetworkAdapters()
+	adaptadores := make([]repository.AdapterInfo, 0, len(adapters))
+	for _, a := range adapters {
+		adaptadores = append(adaptadores, repository.AdapterInfo{
+			Nombre:      a.Name,
+			Tipo:        a.AdapterType,
+			MacAddress:  core.NormalizeMac(a.MacAddress),
+			Estado:      a.Status,
+			EsEthernet:  a.IsEthernet,
+			EsActivo:    a.IsActive,
+			EsPrincipal: a.SameInterface(principal),
+
+			Fabricante:   a.Vendor,
+			MacLocal:     a.LocallyAdministered,
+			MacAleatoria: a.RandomMac,
+		})
+	}
+	logInfo(fmt.Sprintf("Adaptadores relevados: %d", len(adaptadores)))
+	return adaptadores
+}
+
+// spoolCapture guarda la captura en disco para no perderla cuando la base no
+// esta disponible, con el mismo capture_id del log. Se reenvia luego con la
+// opcion de sincronizar.
+func spoolCapture(equipo repository.EquipoInfo, cause error) error {
+	spool := newSpool()
+	path, err := spool.Enqueue(equipo, cause.Error())
+	if err != nil {
+		logError("No se pudo guardar captura en spool", err)
+		return newError(errorKindOf(cause), fmt.Errorf("no se pudo guardar en DB ni en spool: %v (spool: %v)", cause, err))
+	}
+
+	logWarning(fmt.Sprintf("Captura guardada en spool: %s", path))
+
+	if err := emit(spooledRecord(equipo, path), func() { printSpooled(cause, path) }); err != nil {
+		return err
+	}
+	return errCaptureSpooled
+}
+
+func printSpooled(cause error, path string) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("[!] CAPTURA GUARDADA SIN CONEXION")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("\nMotivo:  %v\n", cause)
+	fmt.Printf("Archivo: %s\n", path)
+	fmt.Println("\nUse la opcion [3] o el comando 'sync' cuando haya conexion.")
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+func syncSpool() error {
+	spool := newSpool()
+
+	pending, err := spool.Pending()
+	if err != nil {
+		logError("Error leyendo spool", err)
+		return errorf(kindUnexpected, "no se pudo leer el spool: %v", err)
+	}
+	if len(pending) == 0 {
+		return emitList(nil, syncRecord("", "", ""), func() {
+			fmt.Println("\n[OK] No hay capturas pendientes")
+		})
+	}
+
+	store, err := initStore()
+	if err != nil {
+		logError("Error de conexion a DB", err)
+		return errorf(kindDBConnection, "%d captura(s) siguen pendientes: %v", len(pending), err)
+	}
+	defer store.Close()
+
+	fmt.Printf("\n>> Sincronizando %d captura(s)...\n", len(pending))
+	report, syncErr := spool.Sync(store)
+	if syncErr != nil {
+		logError("Sincronizacion incompleta", syncErr)
+		syncErr = saveError(syncErr)
+	}
+
+	logInfo(fmt.Sprintf("Sincronizacion: %d enviadas, %d omitidas, %d rechazadas, %d pendientes",
+		len(report.Enviados), len(report.Omitidos), len(report.Rechazados), len(report.Pendientes)))
+
+	if err := emitList(syncRecords(report), syncRecord("", "", ""), func() { printSyncReport(report) }); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func printSyncReport(report *repository.SyncReport) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("       RESULTADO DE SINCRONIZACION")
+	fmt.Println(strings.Repeat("=", 60))
+
+	sections := []struct {
+		titulo string
+		items  []string
+	}{
+		{"Enviadas", report.Enviados},
+		{"Omitidas (ya registradas)", report.Omitidos},
+		{"Rechazadas", report.Rechazados},
+		{"Pendientes", report.Pendientes},
+	}
+	for _, sec := range sections {
+		fmt.Printf("\n%s: %d\n", sec.titulo, len(sec.items))
+		for _, item := range sec.items {
+			fmt.Printf("  - %s\n", item)
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+func reportDominio() error {
+	store, err := initStore()
+	if err != nil {
+		logError("Error de conexion a DB", err)
+		return newError(kindDBConnection, err)
+	}
+	defer store.Close()
+
+	resumen, err := store.ResumenDominioPorPiso()
+	if err != nil {
+		logError("Error consultando resumen de dominio", err)
+		return newError(kindDBQuery, err)
+	}
+
+	return emitList(dominioRecords(resumen), dominioRecord(repository.DominioResumen{}), func() { printDominio(resumen) })
+}
+
+func printDominio(resumen []repository.DominioResumen) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("       EQUIPOS POR PISO SEGUN DOMINIO")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("\n%-10s %8s %10s %10s %14s\n", "Piso", "Total", "Dominio", "mec.local", "Fuera dominio")
+
+	var total, fuera int64
+	for _, r := range resumen {
+		fmt.Printf("%-10s %8d %10d %10d %14d\n", r.Piso, r.Total, r.EnDominio, r.EsMecLocal, r.FueraDominio)
+		total += r.Total
+		fuera += r.FueraDominio
+	}
+
+	fmt.Printf("\nTotal: %d equipos, %d fuera de dominio\n", total, fuera)
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+// findMac busca una MAC entre todos los adaptadores capturados, para los
+// pedidos de NAC y DHCP que llegan con la MAC de un Wi-Fi o un dock.
+func findMac(macAddress string) error {
+	store, err := initStore()
+	if err != nil {
+		logError("Error de conexion a DB", err)
+		return newError(kindDBConnection, err)
+	}
+	defer store.Close()
+
+	macAddress = core.NormalizeMac(macAddress)
+	adapters, err := store.FindAdaptersByMac(macAddress)
+	if err != nil {
+		logError("Error buscando MAC", err)
+		return newError(kindDBQuery, err)
+	}
+
+	return emitList(equipoAdapterRecords(adapters), equipoAdapterRecord(repository.EquipoAdapter{}), func() {
+		if len(adapters) == 0 {
+			fmt.Printf("[!] La MAC %s no figura en ninguna captura\n", macAddress)
+			return
+		}
+		fmt.Printf("\n%-6s %-20s %-19s %-19s %-30s %s\n", "ID", "Equipo", "Fecha", "MAC", "Adaptador", "Principal")
+		for _, a := range adapters {
+			fmt.Printf("%-6d %-20s %-19s %-19s %-30s %s\n", a.EquipoID, a.ComputerName, a.FechaRelevamiento, a.MacAddress, a.Nombre, textValue(a.EsPrincipal))
+		}
+	})
+}
+
+// newCaptureID identifica una ejecucion de captura. Va en el log y en la
+// entrada del spool para seguirla hasta que se sincroniza.
+func newCaptureID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("150405.000000")
+	}
+	return hex.EncodeToString(b)
 }
 
-func handlePanic() {
+func newSpool() *repository.Spool {
+	return repository.NewSpool(getSpoolDir(), os.Getenv("SPOOL_KEY"))
+}
+
+func getSpoolDir() string {
+	if dir := os.Getenv("SPOOL_DIR"); dir != "" {
+		return dir
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return "spool"
+	}
+	return filepath.Join(filepath.Dir(exePath), "spool")
+}
+
+func handlePanic(code *int, interactive bool) {
 	if r := recover(); r != nil {
 		logError("PANIC DETECTADO", fmt.Errorf("%v", r))
 		fmt.Printf("\n[ERROR CRITICO] El programa encontro un error inesperado\n")
 		fmt.Printf("Error: %v\n", r)
 		fmt.Printf("\nStack trace:\n%s\n", debug.Stack())
 		fmt.Printf("\nRevise el archivo error.log para mas detalles\n")
-		waitForExit()
+		*code = exitCode(newError(kindUnexpected, nil))
+		if interactive {
+			waitForExit()
+		}
 	}
 }
 
 func waitForExit() {
 	fmt.Println("\nPresione Enter para salir...")
-	fmt.Scanln()
+	core.ReadConsoleLine(0)
 }
 
 func initLogging() {
-	exePath, err := os.Executable()
-	if err != nil {
-		log.Printf("No se pudo obtener ruta del ejecutable: %v", err)
-		return
+	logPath := "error.log"
+	if exePath, err := os.Executable(); err == nil {
+		logPath = filepath.Join(filepath.Dir(exePath), "error.log")
 	}
-	
-	exeDir := filepath.Dir(exePath)
-	logPath := filepath.Join(exeDir, "error.log")
 
-	logFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	var err error
+	logger, err = logging.Open(logPath, logRotation())
 	if err != nil {
-		log.Printf("No se pudo crear archivo de log: %v", err)
-		return
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	if err := applyLogOptions(); err != nil {
+		logWarning(err.Error())
 	}
 
-	log.SetOutput(logFile)
+	log.SetFlags(0)
+	log.SetOutput(logger.Writer(logging.LevelInfo))
 	logInfo("========== NUEVA EJECUCION ==========")
 }
 
+// applyLogOptions toma nivel y formato de los flags o, si no se indicaron,
+// de LOG_LEVEL y LOG_FORMAT. Se vuelve a llamar despues de cargar el .env.
+func applyLogOptions() error {
+	if logger == nil {
+		return nil
+	}
+
+	levelName := options.logLevel
+	if levelName == "" {
+		levelName = os.Getenv("LOG_LEVEL")
+	}
+	level, err := logging.ParseLevel(levelName)
+	if err != nil {
+		if options.logLevel != "" {
+			return usageErrorf("%v", err)
+		}
+		logWarning(err.Error())
+	}
+	logger.SetLevel(level)
+
+	format := options.logFormat
+	if format == "" {
+		format = getEnv("LOG_FORMAT", "text")
+	}
+	switch format {
+	case "text", "json":
+		logger.SetJSON(format == "json")
+	default:
+		if options.logFormat != "" {
+			return usageErrorf("formato de log invalido: %s (use text o json)", format)
+		}
+		logWarning(fmt.Sprintf("LOG_FORMAT invalido: %s", format))
+	}
+
+	logger.SetRotation(logRotation())
+	return nil
+}
+
+func logRotation() logging.Rotation {
+	return logging.Rotation{
+		MaxSizeMB:  getEnvInt("LOG_MAX_SIZE_MB", 5),
+		MaxAgeDays: getEnvInt("LOG_MAX_AGE_DAYS", 30),
+		MaxBackups: getEnvInt("LOG_MAX_BACKUPS", 5),
+	}
+}
+
 func closeLogging() {
-	if logFile != nil {
-		logFile.Close()
+	if logger != nil {
+		logger.Close()
+	}
+}
+
+// setLogField agrega contexto (equipo, MAC, captura) a los eventos
+// siguientes del log.
+func setLogField(key, value string) {
+	if logger != nil {
+		logger.SetField(key, value)
+	}
+}
+
+func logDebug(msg string) {
+	if logger != nil {
+		logger.Debug(msg)
 	}
 }
 
 func logInfo(msg string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logMsg := fmt.Sprintf("[INFO] %s - %s", timestamp, msg)
-	if logFile != nil {
-		logFile.WriteString(logMsg + "\n")
+	if logger != nil {
+		logger.Info(msg)
 	}
-	log.Println(logMsg)
 }
 
 func logWarning(msg string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logMsg := fmt.Sprintf("[WARNING] %s - %s", timestamp, msg)
-	if logFile != nil {
-		logFile.WriteString(logMsg + "\n")
+	if logger != nil {
+		logger.Warning(msg)
 	}
-	log.Println(logMsg)
 }
 
 func logError(msg string, err error) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logMsg := fmt.Sprintf("[ERROR] %s - %s", timestamp, msg)
-	if err != nil {
-		logMsg += fmt.Sprintf(" - %v", err)
+	if logger != nil {
+		logger.Error(msg, err)
 	}
-	if logFile != nil {
-		logFile.WriteString(logMsg + "\n")
-	}
-	log.Println(logMsg)
 }
 
 func validateEnvironment() error {
-	computerName := os.Getenv("COMPUTERNAME")
+	computerName := core.ComputerName()
 	if computerName == "" {
-		return fmt.Errorf("variable COMPUTERNAME no disponible")
+		return fmt.Errorf("nombre del equipo no disponible (COMPUTERNAME u hostname)")
 	}
 
 	exePath, err := os.Executable()
@@ -280,16 +945,70 @@ func printSuccess(result *repository.EquipoResult) {
 	
 	if result.VerifiedData != nil {
 		v := result.VerifiedData
-		fmt.Printf("\nID:        %d\n", v.ID)
+		estado := "Actualizado"
+		if result.Created {
+			estado = "Nuevo"
+		}
+		fmt.Printf("\nID:        %d (%s)\n", v.ID, estado)
 		fmt.Printf("Equipo:    %s\n", v.ComputerName)
 		fmt.Printf("MAC:       %s\n", v.MacAddress)
+		if v.MacFabricante != "" {
+			fmt.Printf("Placa:     %s\n", v.MacFabricante)
+		}
 		fmt.Printf("IP:        %s\n", v.IPAddress)
 		fmt.Printf("Ubicacion: Piso %s - %s\n", v.Piso, v.Oficina)
+		dominio := "NO (fuera de dominio)"
+		if v.EnDominio {
+			dominio = v.NombreDominio
+		}
+		fmt.Printf("Dominio:   %s\n", dominio)
+		fmt.Printf("\nEquipo:    %s %s\n", v.Fabricante, v.Modelo)
+		fmt.Printf("Serie:     %s\n", valueOrDash(v.SerialNumber))
+		fmt.Printf("BIOS:      %s\n", valueOrDash(v.BIOSVersion))
+		fmt.Printf("SO:        %s\n", valueOrDash(v.SistemaOperativo))
+		fmt.Printf("CPU:       %s\n", valueOrDash(v.Procesador))
+		if v.MemoriaRAMMB > 0 {
+			fmt.Printf("RAM:       %d MB\n", v.MemoriaRAMMB)
+		} else {
+			fmt.Println("RAM:       -")
+		}
 	}
 	
 	fmt.Println(strings.Repeat("=", 60))
 }
 
+func initStore() (repository.EquipoStore, error) {
+	driver := getEnv("DB_DRIVER", repository.DriverMySQL)
+	if err := repository.ValidateDriver(driver); err != nil {
+		return nil, err
+	}
+	logInfo(fmt.Sprintf("Backend de almacenamiento: %s", driver))
+
+	switch driver {
+	case repository.DriverSQLite:
+		return repository.OpenSQLiteStore(getSQLitePath())
+	case repository.DriverMemory:
+		return repository.NewMemoryStore(), nil
+	}
+
+	db, err := initDB()
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewMySQLStore(db), nil
+}
+
+func getSQLitePath() string {
+	if path := os.Getenv("SQLITE_PATH"); path != "" {
+		return path
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return "relevamiento.db"
+	}
+	return filepath.Join(filepath.Dir(exePath), "relevamiento.db")
+}
+
 func initDB() (*sql.DB, error) {
 	requiredVars := []string{"DB_USER", "DB_PASS", "DB_HOST", "DB_PORT", "DB_NAME"}
 	for _, varName := range requiredVars {
@@ -317,55 +1036,62 @@ func initDB() (*sql.DB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Sin prefijo: quien llama lo envuelve en kindDBConnection, que ya dice
+	// que no se pudo conectar.
 	if err := db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("no se pudo conectar a la base de datos: %v", err)
+		db.Close()
+		return nil, err
 	}
 
 	logInfo("Conexion a DB exitosa")
 	return db, nil
 }
 
-func getIPAddress() string {
-	addrs, err := net.InterfaceAddrs()
-
-	networkPrefix := os.Getenv("NETWORK_PREFIX")
-	if networkPrefix == "" {
-		logError("NETWORK_PREFIX no configurado", nil)
-		log.Fatal("Error: NETWORK_PREFIX no configurado en .env")
-	}
-
+// getIPAddress toma la IP del adaptador seleccionado, asi MAC e IP salen del
+// mismo adaptador, y la elige segun los rangos de red configurados. Solo si
+// ese adaptador no tiene IP (por ejemplo cuando se detecto con getmac) se
+// busca en el resto de las interfaces, con una advertencia porque la IP
+// puede ser de Wi-Fi o VPN.
+func getIPAddress(adapter core.NetworkAdapter) (core.AddressMatch, error) {
+	address, fromSystem, err := core.AdapterAddress(adapter)
 	if err != nil {
 		logError("Error obteniendo direcciones de red", err)
-		return "No disponible"
+		return address, newError(kindNoIP, err)
 	}
+	if fromSystem {
+		if address.IP == "" {
+			return address, nil
+		}
 
-	var fallbackIP string
+		fmt.Printf("\n[!] ADVERTENCIA: La IP %s no pudo asociarse al adaptador %s (%s)\n", address.IP, adapter.Name, adapter.MacAddress)
+		logWarning(fmt.Sprintf("IP no correlacionada con el adaptador %s (%s): se usa %s de otra interfaz", adapter.Name, adapter.MacAddress, address.IP))
+	}
 
-	for _, addr := range addrs {
-		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
-			if ipnet.IP.To4() != nil {
-				ip := ipnet.IP.String()
+	if !address.Matched {
+		fmt.Printf("\n[!] ADVERTENCIA: La IP %s esta %s\n", address.IP, address.RangeLabel())
+		logWarning(fmt.Sprintf("La IP %s del adaptador %s esta %s", address.IP, adapter.Name, address.RangeLabel()))
+	}
 
-				if strings.HasPrefix(ip, "169.254.") {
-					continue
-				}
+	return address, nil
+}
 
-				if strings.HasPrefix(ip, networkPrefix) {
-					return ip
-				}
+func printEquipoTable(e repository.EquipoInfo) {
+	printRecordText("[DRY-RUN] REGISTRO DETECTADO (NO GUARDADO)", equipoRecord(e))
+}
 
-				if fallbackIP == "" {
-					fallbackIP = ip
-				}
-			}
-		}
+func valueOrDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
 	}
+	return value
+}
 
-	if fallbackIP != "" {
-		return fallbackIP
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
 	}
-
-	return "No disponible"
+	return value
 }
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"relevamiento/repository"
+	"strconv"
+	"strings"
+)
+
+func runMigrate(args []string) error {
+	action := "status"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "up", "down", "status":
+	default:
+		return usageErrorf("accion de migrate invalida: %s (use up, down o status)", action)
+	}
+
+	steps := 1
+	if action == "down" && len(args) > 1 {
+		var err error
+		steps, err = strconv.Atoi(args[1])
+		if err != nil || steps < 1 {
+			return usageErrorf("cantidad de pasos invalida: %s", args[1])
+		}
+	}
+
+	db, driver, err := openMigrationDB()
+	if err != nil {
+		logError("Error de conexion a DB", err)
+		return newError(kindDBConnection, err)
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		applied, err := repository.MigrateUp(db, driver)
+		for _, m := range applied {
+			logInfo(fmt.Sprintf("Migracion aplicada: %04d_%s", m.Version, m.Name))
+		}
+		if emitErr := emitMigrations(applied, "aplicada", err == nil, "[OK] La base ya esta actualizada"); emitErr != nil {
+			return emitErr
+		}
+		if err != nil {
+			logError("Error aplicando migraciones", err)
+			return newError(kindDBMigration, err)
+		}
+
+	case "down":
+		reverted, err := repository.MigrateDown(db, driver, steps)
+		for _, m := range reverted {
+			logInfo(fmt.Sprintf("Migracion revertida: %04d_%s", m.Version, m.Name))
+		}
+		if emitErr := emitMigrations(reverted, "revertida", err == nil, "[OK] No hay migraciones para revertir"); emitErr != nil {
+			return emitErr
+		}
+		if err != nil {
+			logError("Error revirtiendo migraciones", err)
+			return newError(kindDBMigration, err)
+		}
+
+	case "status":
+		status, err := repository.GetMigrationStatus(db, driver)
+		if err != nil {
+			logError("Error consultando migraciones", err)
+			return newError(kindDBMigration, err)
+		}
+		return emitList(migrationRecords(status), migrationRecord(repository.MigrationStatus{}), func() {
+			printMigrationStatus(driver, status)
+		})
+	}
+
+	return nil
+}
+
+// emitMigrations informa las migraciones que up o down llegaron a aplicar o
+// revertir, incluso si una posterior fallo. none se muestra si no hubo
+// cambios y el comando termino bien.
+func emitMigrations(migrations []repository.Migration, resultado string, ok bool, none string) error {
+	records := make([]record, 0, len(migrations))
+	for _, m := range migrations {
+		records = append(records, migrationChangeRecord(m, resultado))
+	}
+
+	return emitList(records, migrationChangeRecord(repository.Migration{}, ""), func() {
+		for _, m := range migrations {
+			fmt.Printf("[OK] %s%s %04d_%s\n", strings.ToUpper(resultado[:1]), resultado[1:], m.Version, m.Name)
+		}
+		if ok && len(migrations) == 0 {
+			fmt.Println(none)
+		}
+	})
+}
+
+func openMigrationDB() (*sql.DB, string, error) {
+	driver := getEnv("DB_DRIVER", repository.DriverMySQL)
+	if err := repository.ValidateDriver(driver); err != nil {
+		return nil, "", err
+	}
+
+	switch driver {
+	case repository.DriverSQLite:
+		db, err := repository.OpenSQLiteDB(getSQLitePath())
+		return db, driver, err
+	case repository.DriverMemory:
+		return nil, "", fmt.Errorf("el backend en memoria no usa migraciones")
+	}
+
+	db, err := initDB()
+	return db, driver, err
+}
+
+func printMigrationStatus(driver string, status []repository.MigrationStatus) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Printf("       MIGRACIONES (%s)\n", driver)
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, m := range status {
+		estado := "pendiente"
+		if m.Applied {
+			estado = "aplicada " + m.AppliedAt
+		}
+		fmt.Printf("%04d  %-30s %s\n", m.Version, m.Name, estado)
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"relevamiento/core"
+	"relevamiento/repository"
+	"strings"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+)
+
+// stdout recibe la salida estructurada. En modo json/csv os.Stdout se
+// redirige a stderr, de modo que los mensajes de progreso (incluidos los que
+// imprime core) no ensucian lo que consumen los scripts.
+var stdout io.Writer = os.Stdout
+
+var outputRedirected bool
+
+func setupOutput() error {
+	switch options.output {
+	case outputText:
+		return nil
+	case outputJSON, outputCSV:
+		if !outputRedirected {
+			stdout = os.Stdout
+			os.Stdout = os.Stderr
+			outputRedirected = true
+		}
+		return nil
+	}
+	return usageErrorf("formato de salida invalido: %s (use text, json o csv)", options.output)
+}
+
+type field struct {
+	Key   string
+	Label string
+	Value interface{}
+}
+
+// record es un conjunto ordenado de campos. Se serializa como objeto JSON
+// respetando el orden, o como una fila CSV.
+type record []field
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// emit escribe un registro en el formato elegido. En modo texto delega en
+// la funcion text, que conserva la presentacion para el tecnico.
+func emit(r record, text func()) error {
+	switch options.output {
+	case outputJSON:
+		return writeJSON(r)
+	case outputCSV:
+		return writeCSV(r, []record{r})
+	}
+
+	text()
+	return nil
+}
+
+// emitList es emit para listas. columns es un registro de ejemplo que da el
+// encabezado CSV aunque la lista este vacia.
+func emitList(records []record, columns record, text func()) error {
+	switch options.output {
+	case outputJSON:
+		if records == nil {
+			records = []record{}
+		}
+		return writeJSON(records)
+	case outputCSV:
+		return writeCSV(columns, records)
+	}
+
+	text()
+	return nil
+}
+
+func writeJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando salida: %v", err)
+	}
+	fmt.Fprintln(stdout, string(data))
+	return nil
+}
+
+func writeCSV(columns record, records []record) error {
+	w := csv.NewWriter(stdout)
+
+	header := make([]string, len(columns))
+	for i, f := range columns {
+		header[i] = f.Key
+	}
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("error escribiendo CSV: %v", err)
+	}
+
+	for _, r := range records {
+		row := make([]string, len(r))
+		for i, f := range r {
+			row[i] = csvValue(f.Value)
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("error escribiendo CSV: %v", err)
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func printRecordText(title string, r record) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	for _, f := range r {
+		fmt.Printf("%-16s %s\n", f.Label+":", textValue(f.Value))
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+func textValue(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	if b, ok := v.(bool); ok {
+		if b {
+			return "SI"
+		}
+		return "NO"
+	}
+	return valueOrDash(fmt.Sprint(v))
+}
+
+func csvValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// optionalBool y optionalInt devuelven nil para un dato que no se pudo
+// determinar: null en JSON, vacio en CSV y "-" en texto.
+func optionalBool(v *bool) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func optionalInt(v int64) interface{} {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// equipoRecord enumera todos los campos del registro en el orden en que se
+// muestran, incluidos los vacios.
+func equipoRecord(e repository.EquipoInfo) record {
+	return record{
+		{"capture_id", "Captura", e.CaptureID},
+		{"fecha_relevamiento", "Fecha", e.FechaRelevamiento},
+		{"computer_name", "Equipo", e.ComputerName},
+		{"nombre_anterior", "Nombre anterior", e.NombreAnterior},
+		{"mac_address", "MAC", e.MacAddress},
+		{"mac_fabricante", "Fabricante MAC", e.MacFabricante},
+		{"adaptador_principal", "Adaptador", e.AdaptadorPrincipal},
+		{"motivo_seleccion", "Seleccion", e.MotivoSeleccion},
+		{"ip_address", "IP", e.IPAddress},
+		{"rango_red", "Rango de red", e.RangoRed},
+		{"edificio", "Edificio", e.Edificio},
+		{"piso", "Piso", e.Piso},
+		{"oficina", "Oficina", e.Oficina},
+		{"en_dominio", "En dominio", e.EnDominio},
+		{"nombre_dominio", "Dominio", e.NombreDominio},
+		{"es_mec_local", "mec.local", e.EsMecLocal},
+		{"gateway", "Gateway", e.Gateway},
+		{"servidores_dns", "DNS", e.ServidoresDNS},
+		{"dhcp_habilitado", "DHCP", optionalBool(e.DHCPHabilitado)},
+		{"servidor_dhcp", "Servidor DHCP", e.ServidorDHCP},
+		{"lease_obtenido", "Lease obtenido", e.LeaseObtenido},
+		{"lease_expira", "Lease expira", e.LeaseExpira},
+		{"velocidad_mbps", "Velocidad (Mb/s)", optionalInt(e.VelocidadMbps)},
+		{"duplex", "Duplex", e.Duplex},
+		{"fabricante", "Fabricante", e.Fabricante},
+		{"modelo", "Modelo", e.Modelo},
+		{"serial_number", "Serie", e.SerialNumber},
+		{"bios_version", "BIOS", e.BIOSVersion},
+		{"sistema_operativo", "SO", e.SistemaOperativo},
+		{"version_so", "Version SO", e.VersionSO},
+		{"arquitectura", "Arquitectura", e.Arquitectura},
+		{"procesador", "CPU", e.Procesador},
+		{"memoria_ram", "RAM", e.MemoriaRAM},
+		{"memoria_ram_mb", "RAM (MB)", e.MemoriaRAMMB},
+		{"usuario_actual", "Usuario", e.UsuarioActual},
+	}
+}
+
+func captureRecord(e repository.EquipoInfo, result *repository.EquipoResult) record {
+	r := record{
+		{"estado", "Estado", "guardado"},
+		{"id", "ID", result.InsertedID},
+		{"creado", "Nuevo", result.Created},
+		{"historial_id", "Historial", result.HistorialID},
+		{"archivo_spool", "Spool", ""},
+	}
+	return append(r, equipoRecord(e)...)
+}
+
+func spooledRecord(e repository.EquipoInfo, path string) record {
+	r := record{
+		{"estado", "Estado", "pendiente"},
+		{"id", "ID", int64(0)},
+		{"creado", "Nuevo", false},
+		{"historial_id", "Historial", int64(0)},
+		{"archivo_spool", "Spool", path},
+	}
+	return append(r, equipoRecord(e)...)
+}
+
+func adapterRecord(a core.NetworkAdapter) record {
+	return record{
+		{"name", "Nombre", a.Name},
+		{"adapter_type", "Tipo", a.AdapterType},
+		{"mac_address", "MAC", a.MacAddress},
+		{"vendor", "Fabricante", a.Vendor},
+		{"locally_administered", "MAC local", a.LocallyAdministered},
+		{"random_mac", "MAC aleatoria", a.RandomMac},
+		{"status", "Estado", a.Status},
+		{"is_ethernet", "Ethernet", a.IsEthernet},
+		{"is_active", "Activo", a.IsActive},
+		{"hardware_type", "Hardware", a.HardwareType},
+		{"index", "Indice", a.Index},
+		{"mtu", "MTU", a.MTU},
+		{"flags", "Flags", a.Flags},
+		{"speed", "Velocidad", a.Speed},
+		{"duplex", "Duplex", a.Duplex},
+		{"ip_address", "IP", a.IPAddress},
+		{"ipv4", "IPv4", strings.Join(a.IPv4, ";")},
+		{"ipv6", "IPv6", strings.Join(a.IPv6, ";")},
+		{"gateway", "Gateway", a.Gateway},
+		{"addresses", "Direcciones", strings.Join(a.Addresses, ";")},
+		{"source", "Fuente", a.Source},
+		{"rule", "Regla", a.Rule},
+	}
+}
+
+func explainRecord(a core.NetworkAdapter, decision core.RuleDecision) record {
+	accion := core.RuleExclude
+	if decision.Include {
+		accion = core.RuleInclude
+	}
+	return record{
+		{"name", "Nombre", a.Name},
+		{"description", "Descripcion", a.AdapterType},
+		{"mac_address", "MAC", a.MacAddress},
+		{"vendor", "Fabricante", a.Vendor},
+		{"hardware_type", "Hardware", a.HardwareType},
+		{"source", "Fuente", a.Source},
+		{"action", "Resultado", accion},
+		{"rule_index", "N", decision.Index},
+		{"rule", "Regla", decision.Rule},
+	}
+}
+
+func equipoAdapterRecord(a repository.EquipoAdapter) record {
+	return record{
+		{"equipo_id", "ID", a.EquipoID},
+		{"computer_name", "Equipo", a.ComputerName},
+		{"historial_id", "Historial", a.HistorialID},
+		{"fecha_relevamiento", "Fecha", a.FechaRelevamiento},
+		{"nombre", "Adaptador", a.Nombre},
+		{"tipo", "Tipo", a.Tipo},
+		{"mac_address", "MAC", a.MacAddress},
+		{"fabricante", "Fabricante", a.Fabricante},
+		{"estado", "Estado", a.Estado},
+		{"es_ethernet", "Ethernet", a.EsEthernet},
+		{"es_activo", "Activo", a.EsActivo},
+		{"es_principal", "Principal", a.EsPrincipal},
+	}
+}
+
+func equipoAdapterRecords(adapters []repository.EquipoAdapter) []record {
+	records := make([]record, 0, len(adapters))
+	for _, a := range adapters {
+		records = append(records, equipoAdapterRecord(a))
+	}
+	return records
+}
+
+func preflightRecord(c core.PreflightCheck) record {
+	return record{
+		{"check", "Verificacion", c.Name},
+		{"status", "Resultado", c.Status},
+		{"detail", "Detalle", c.Detail},
+		{"duration_ms", "Tiempo (ms)", c.Duration.Milliseconds()},
+	}
+}
+
+func preflightRecords(checks []core.PreflightCheck) []record {
+	records := make([]record, 0, len(checks))
+	for _, c := range checks {
+		records = append(records, preflightRecord(c))
+	}
+	return records
+}
+
+func systemRecord(info core.SystemInfo, report core.SystemInfoReport, serialNumber, biosVersion string) record {
+	unresolved := make([]string, 0, len(report.Unresolved))
+	for _, u := range report.Unresolved {
+		unresolved = append(unresolved, u.Field)
+	}
+
+	return record{
+		{"os", "SO", info.OS},
+		{"version", "Version", info.Version},
+		{"architecture", "Arquitectura", info.Architecture},
+		{"memory_ram", "RAM", info.MemoryRAM},
+		{"memory_ram_mb", "RAM (MB)", core.ParseMemoryMB(info.MemoryRAM)},
+		{"processor", "CPU", info.Processor},
+		{"current_user", "Usuario", info.CurrentUser},
+		{"manufacturer", "Fabricante", info.Manufacturer},
+		{"model", "Modelo", info.Model},
+		{"serial_number", "Serie", serialNumber},
+		{"bios_version", "BIOS", biosVersion},
+		{"processors", "Procesadores", strings.Join(info.Processors, ";")},
+		{"hotfixes", "Revisiones", strings.Join(info.Hotfixes, ";")},
+		{"systeminfo_columns", "Columnas", report.Columns},
+		{"unresolved", "Sin resolver", strings.Join(unresolved, ";")},
+	}
+}
+
+func configRecord(config *core.LocationConfig, path string) record {
+	return record{
+		{"piso", "Piso", config.Piso},
+		{"oficina", "Oficina", config.Oficina},
+		{"archivo", "Archivo", path},
+	}
+}
+
+// resetRecord informa si reset-config elimino un archivo o no habia nada.
+func resetRecord(path string, eliminado bool) record {
+	return record{
+		{"archivo", "Archivo", path},
+		{"eliminado", "Eliminado", eliminado},
+	}
+}
+
+func syncRecord(archivo, resultado, detalle string) record {
+	return record{
+		{"archivo", "Archivo", archivo},
+		{"resultado", "Resultado", resultado},
+		{"detalle", "Detalle", detalle},
+	}
+}
+
+func syncRecords(report *repository.SyncReport) []record {
+	records := []record{}
+	add := func(resultado string, items []string) {
+		for _, item := range items {
+			archivo, detalle := item, ""
+			if i := strings.Index(item, ": "); i >= 0 {
+				archivo, detalle = item[:i], item[i+2:]
+			}
+			records = append(records, syncRecord(archivo, resultado, detalle))
+		}
+	}
+	add("enviado", report.Enviados)
+	add("omitido", report.Omitidos)
+	add("rechazado", report.Rechazados)
+	add("pendiente", report.Pendientes)
+	return records
+}
+
+func dominioRecord(r repository.DominioResumen) record {
+	return record{
+		{"piso", "Piso", r.Piso},
+		{"total", "Total", r.Total},
+		{"en_dominio", "Dominio", r.EnDominio},
+		{"es_mec_local", "mec.local", r.EsMecLocal},
+		{"fuera_dominio", "Fuera dominio", r.FueraDominio},
+	}
+}
+
+func dominioRecords(resumen []repository.DominioResumen) []record {
+	records := make([]record, 0, len(resumen))
+	for _, r := range resumen {
+		records = append(records, dominioRecord(r))
+	}
+	return records
+}
+
+func migrationRecord(m repository.MigrationStatus) record {
+	return record{
+		{"version", "Version", m.Version},
+		{"name", "Nombre", m.Name},
+		{"applied", "Aplicada", m.Applied},
+		{"applied_at", "Fecha", m.AppliedAt},
+	}
+}
+
+func migrationRecords(status []repository.MigrationStatus) []record {
+	records := make([]record, 0, len(status))
+	for _, m := range status {
+		records = append(records, migrationRecord(m))
+	}
+	return records
+}
+
+// migrationChangeRecord es una migracion que migrate up o down aplico o
+// revirtio.
+func migrationChangeRecord(m repository.Migration, resultado string) record {
+	return record{
+		{"version", "Version", m.Version},
+		{"name", "Nombre", m.Name},
+		{"resultado", "Resultado", resultado},
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"relevamiento/repository"
+)
+
+// captureOutput ejecuta fn con el formato indicado y devuelve lo escrito en
+// stdout y si se llamo a la salida de texto.
+func captureOutput(t *testing.T, format string, fn func(text func()) error) (string, bool) {
+	t.Helper()
+
+	prevOutput, prevStdout := options.output, stdout
+	t.Cleanup(func() { options.output, stdout = prevOutput, prevStdout })
+
+	var buf bytes.Buffer
+	options.output, stdout = format, &buf
+
+	called := false
+	if err := fn(func() { called = true }); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String(), called
+}
+
+func TestEmit(t *testing.T) {
+	r := record{
+		{"piso", "Piso", "3"},
+		{"oficina", "Oficina", "Compras, Tesoreria"},
+		{"id", "ID", int64(7)},
+		{"en_dominio", "En dominio", true},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+		text   bool
+	}{
+		{outputJSON, "{\n  \"piso\": \"3\",\n  \"oficina\": \"Compras, Tesoreria\",\n  \"id\": 7,\n  \"en_dominio\": true\n}\n", false},
+		{outputCSV, "piso,oficina,id,en_dominio\n3,\"Compras, Tesoreria\",7,true\n", false},
+		{outputText, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, text := captureOutput(t, tt.format, func(text func()) error { return emit(r, text) })
+			if got != tt.want || text != tt.text {
+				t.Errorf("emit() = %q (texto %v), se esperaba %q (texto %v)", got, text, tt.want, tt.text)
+			}
+		})
+	}
+}
+
+func TestEmitList(t *testing.T) {
+	columns := syncRecord("", "", "")
+	report := &repository.SyncReport{
+		Enviados:   []string{"a.json"},
+		Rechazados: []string{"b.json: checksum invalido"},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		records []record
+		want    string
+	}{
+		{"json vacio", outputJSON, nil, "[]\n"},
+		{"csv vacio con encabezado", outputCSV, nil, "archivo,resultado,detalle\n"},
+		{"csv", outputCSV, syncRecords(report), "archivo,resultado,detalle\na.json,enviado,\nb.json,rechazado,checksum invalido\n"},
+		{"json", outputJSON, syncRecords(report)[:1], "[\n  {\n    \"archivo\": \"a.json\",\n    \"resultado\": \"enviado\",\n    \"detalle\": \"\"\n  }\n]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, text := captureOutput(t, tt.format, func(text func()) error { return emitList(tt.records, columns, text) })
+			if got != tt.want || text {
+				t.Errorf("emitList() = %q (texto %v), se esperaba %q", got, text, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetupOutputRejectsUnknownFormat(t *testing.T) {
+	prev := options.output
+	t.Cleanup(func() { options.output = prev })
+
+	options.output = "xml"
+	if err := setupOutput(); errorKindOf(err) != kindUsage {
+		t.Errorf("setupOutput() = %v, se esperaba un error de uso", err)
+	}
+}
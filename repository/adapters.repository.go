@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"relevamiento/core"
+	"time"
+)
+
+// AdapterInfo es un adaptador de red del equipo al momento de la captura.
+// Se guardan todos (Wi-Fi, docks, USB-Ethernet), no solo el principal.
+type AdapterInfo struct {
+	Nombre      string `json:"nombre"`
+	Tipo        string `json:"tipo,omitempty"`
+	MacAddress  string `json:"mac_address"`
+	Estado      string `json:"estado,omitempty"`
+	EsEthernet  bool   `json:"es_ethernet,omitempty"`
+	EsActivo    bool   `json:"es_activo,omitempty"`
+	EsPrincipal bool   `json:"es_principal,omitempty"`
+
+	Fabricante   string `json:"fabricante,omitempty"`
+	MacLocal     bool   `json:"mac_local,omitempty"`
+	MacAleatoria bool   `json:"mac_aleatoria,omitempty"`
+}
+
+// EquipoAdapter es una fila de equipo_adapter, con el equipo al que
+// pertenece.
+type EquipoAdapter struct {
+	ID                int64
+	EquipoID          int64
+	HistorialID       int64
+	FechaRelevamiento string
+	ComputerName      string
+	AdapterInfo
+}
+
+var adapterColumns = []string{
+	"equipo_id",
+	"historial_id",
+	"fecha_relevamiento",
+	"nombre",
+	"tipo",
+	"mac_address",
+	"estado",
+	"es_ethernet",
+	"es_activo",
+	"es_principal",
+	"fabricante",
+	"mac_local",
+	"mac_aleatoria",
+}
+
+const selectEquipoAdapter = `SELECT a.id, a.equipo_id, a.historial_id, a.fecha_relevamiento,
+		e.computer_name, a.nombre, a.tipo, a.mac_address, a.estado,
+		a.es_ethernet, a.es_activo, a.es_principal, a.fabricante, a.mac_local, a.mac_aleatoria
+	FROM equipo_adapter a
+	JOIN equipo_info e ON e.id = a.equipo_id`
+
+// FindAdaptersByMac busca la MAC entre todos los adaptadores capturados,
+// no solo el principal. Devuelve las capturas mas recientes primero.
+func (s *sqlStore) FindAdaptersByMac(macAddress string) ([]EquipoAdapter, error) {
+	return s.queryAdapters(selectEquipoAdapter+` WHERE a.mac_address = ? ORDER BY a.id DESC`, core.NormalizeMac(macAddress))
+}
+
+// ListAdaptadores devuelve los adaptadores de la ultima captura del equipo.
+func (s *sqlStore) ListAdaptadores(equipoID int64) ([]EquipoAdapter, error) {
+	return s.queryAdapters(selectEquipoAdapter+` WHERE a.historial_id = (
+			SELECT MAX(historial_id) FROM equipo_adapter WHERE equipo_id = ?)
+		ORDER BY a.es_principal DESC, a.id`, equipoID)
+}
+
+func (s *sqlStore) queryAdapters(query string, args ...interface{}) ([]EquipoAdapter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando adaptadores: %v", err)
+	}
+	defer rows.Close()
+
+	adapters := []EquipoAdapter{}
+	for rows.Next() {
+		var a EquipoAdapter
+		if err := rows.Scan(&a.ID, &a.EquipoID, &a.HistorialID, &a.FechaRelevamiento,
+			&a.ComputerName, &a.Nombre, &a.Tipo, &a.MacAddress, &a.Estado,
+			&a.EsEthernet, &a.EsActivo, &a.EsPrincipal,
+			&a.Fabricante, &a.MacLocal, &a.MacAleatoria); err != nil {
+			return nil, fmt.Errorf("error leyendo adaptador: %v", err)
+		}
+		adapters = append(adapters, a)
+	}
+
+	return adapters, rows.Err()
+}
+
+func insertarAdaptadores(ctx context.Context, tx *sql.Tx, equipoID, historialID int64, equipo EquipoInfo) error {
+	query := insertQuery("equipo_adapter", adapterColumns)
+
+	for _, a := range equipo.Adaptadores {
+		_, err := tx.ExecContext(ctx, query,
+			equipoID, historialID, equipo.FechaRelevamiento,
+			a.Nombre, a.Tipo, a.MacAddress, a.Estado,
+			a.EsEthernet, a.EsActivo, a.EsPrincipal,
+			a.Fabricante, a.MacLocal, a.MacAleatoria)
+		if err != nil {
+			return fmt.Errorf("adaptador %s: %v", a.MacAddress, err)
+		}
+	}
+
+	return nil
+}
@@ -4,45 +4,236 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"relevamiento/core"
+	"strings"
 	"time"
 )
 
 type EquipoInfo struct {
-	FechaRelevamiento string
-	ComputerName      string
-	NombreAnterior    string
-	MacAddress        string
-	IPAddress         string
-	Piso              string
-	Oficina           string
+	CaptureID          string `json:"capture_id,omitempty"`
+	FechaRelevamiento  string `json:"fecha_relevamiento"`
+	ComputerName       string `json:"computer_name"`
+	NombreAnterior     string `json:"nombre_anterior"`
+	MacAddress         string `json:"mac_address"`
+	MacFabricante      string `json:"mac_fabricante,omitempty"`
+	AdaptadorPrincipal string `json:"adaptador_principal,omitempty"`
+	MotivoSeleccion    string `json:"motivo_seleccion,omitempty"`
+	IPAddress          string `json:"ip_address"`
+	RangoRed           string `json:"rango_red,omitempty"`
+	Edificio           string `json:"edificio,omitempty"`
+	Piso               string `json:"piso"`
+	Oficina            string `json:"oficina"`
+	SerialNumber       string `json:"serial_number,omitempty"`
+	SistemaOperativo   string `json:"sistema_operativo,omitempty"`
+	VersionSO          string `json:"version_so,omitempty"`
+	Arquitectura       string `json:"arquitectura,omitempty"`
+	MemoriaRAM         string `json:"memoria_ram,omitempty"`
+	MemoriaRAMMB       int64  `json:"memoria_ram_mb,omitempty"`
+	Procesador         string `json:"procesador,omitempty"`
+	UsuarioActual      string `json:"usuario_actual,omitempty"`
+	Fabricante         string `json:"fabricante,omitempty"`
+	Modelo             string `json:"modelo,omitempty"`
+	BIOSVersion        string `json:"bios_version,omitempty"`
+	EnDominio          bool   `json:"en_dominio,omitempty"`
+	NombreDominio      string `json:"nombre_dominio,omitempty"`
+	EsMecLocal         bool   `json:"es_mec_local,omitempty"`
+
+	Gateway        string `json:"gateway,omitempty"`
+	ServidoresDNS  string `json:"servidores_dns,omitempty"`
+	DHCPHabilitado *bool  `json:"dhcp_habilitado,omitempty"`
+	ServidorDHCP   string `json:"servidor_dhcp,omitempty"`
+	LeaseObtenido  string `json:"lease_obtenido,omitempty"`
+	LeaseExpira    string `json:"lease_expira,omitempty"`
+	VelocidadMbps  int64  `json:"velocidad_mbps,omitempty"`
+	Duplex         string `json:"duplex,omitempty"`
+
+	Adaptadores []AdapterInfo `json:"adaptadores,omitempty"`
 }
 
 type EquipoResult struct {
 	Success      bool
 	InsertedID   int64
 	RowsAffected int64
+	Created      bool
+	HistorialID  int64
 	VerifiedData *EquipoVerificado
 	ErrorMessage string
 }
 
 type EquipoVerificado struct {
-	ID           int64
-	ComputerName string
-	IPAddress    string
-	MacAddress   string
-	Oficina      string
+	ID               int64
+	ComputerName     string
+	IPAddress        string
+	MacAddress       string
+	MacFabricante    string
+	Oficina          string
+	Piso             string
+	SerialNumber     string
+	SistemaOperativo string
+	MemoriaRAMMB     int64
+	Procesador       string
+	Fabricante       string
+	Modelo           string
+	BIOSVersion      string
+	EnDominio        bool
+	NombreDominio    string
+	EsMecLocal       bool
+}
+
+// DominioResumen cuenta los equipos de un piso segun su pertenencia al
+// dominio, tomando el estado actual de equipo_info.
+type DominioResumen struct {
 	Piso         string
+	Total        int64
+	EnDominio    int64
+	EsMecLocal   int64
+	FueraDominio int64
+}
+
+// HistorialEntry es una captura puntual de un equipo. equipo_info guarda el
+// estado actual y equipo_historial cada relevamiento realizado.
+type HistorialEntry struct {
+	ID                int64
+	EquipoID          int64
+	CaptureID         string
+	FechaRelevamiento string
+	ComputerName      string
+	MacAddress        string
+	IPAddress         string
+	Piso              string
+	Oficina           string
+	SerialNumber      string
+	MemoriaRAMMB      int64
+	Modelo            string
+	EnDominio         bool
+	NombreDominio     string
+}
+
+// equipoColumns son las columnas comunes a equipo_info y equipo_historial,
+// en el mismo orden que devuelve equipoValues.
+var equipoColumns = []string{
+	"fecha_relevamiento",
+	"computer_name",
+	"mac_address",
+	"ip_address",
+	"piso",
+	"oficina",
+	"serial_number",
+	"sistema_operativo",
+	"version_so",
+	"arquitectura",
+	"memoria_ram",
+	"memoria_ram_mb",
+	"procesador",
+	"usuario_actual",
+	"fabricante",
+	"modelo",
+	"bios_version",
+	"en_dominio",
+	"nombre_dominio",
+	"es_mec_local",
+	"mac_fabricante",
+	"adaptador_principal",
+	"motivo_seleccion",
+	"rango_red",
+	"edificio",
+	"gateway",
+	"servidores_dns",
+	"dhcp_habilitado",
+	"servidor_dhcp",
+	"lease_obtenido",
+	"lease_expira",
+	"velocidad_mbps",
+	"duplex",
+}
+
+func equipoValues(equipo EquipoInfo) []interface{} {
+	return []interface{}{
+		equipo.FechaRelevamiento,
+		equipo.ComputerName,
+		equipo.MacAddress,
+		equipo.IPAddress,
+		equipo.Piso,
+		equipo.Oficina,
+		equipo.SerialNumber,
+		equipo.SistemaOperativo,
+		equipo.VersionSO,
+		equipo.Arquitectura,
+		equipo.MemoriaRAM,
+		equipo.MemoriaRAMMB,
+		equipo.Procesador,
+		equipo.UsuarioActual,
+		equipo.Fabricante,
+		equipo.Modelo,
+		equipo.BIOSVersion,
+		equipo.EnDominio,
+		equipo.NombreDominio,
+		equipo.EsMecLocal,
+		equipo.MacFabricante,
+		equipo.AdaptadorPrincipal,
+		equipo.MotivoSeleccion,
+		equipo.RangoRed,
+		equipo.Edificio,
+		equipo.Gateway,
+		equipo.ServidoresDNS,
+		equipo.DHCPHabilitado,
+		equipo.ServidorDHCP,
+		nullIfEmpty(equipo.LeaseObtenido),
+		nullIfEmpty(equipo.LeaseExpira),
+		nullIfZero(equipo.VelocidadMbps),
+		equipo.Duplex,
+	}
+}
+
+// nullIfEmpty guarda NULL en columnas DATETIME opcionales.
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullIfZero guarda NULL cuando no se pudo determinar la velocidad.
+func nullIfZero(value int64) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+const selectEquipoVerificado = `SELECT id, computer_name, ip_address, mac_address, oficina, piso,
+		serial_number, sistema_operativo, memoria_ram_mb, procesador, fabricante, modelo, bios_version,
+		en_dominio, nombre_dominio, es_mec_local, mac_fabricante
+	FROM equipo_info`
+
+// sqlStore implementa EquipoStore sobre database/sql. Las consultas usan
+// placeholders "?" y SQL comun a MySQL y SQLite.
+type sqlStore struct {
+	db     *sql.DB
+	driver string
 }
 
 func CreateEquiposRepository(db *sql.DB, equipo EquipoInfo) (*EquipoResult, error) {
+	return NewMySQLStore(db).Create(equipo)
+}
+
+func (s *sqlStore) Create(equipo EquipoInfo) (*EquipoResult, error) {
 	result := &EquipoResult{
 		Success: false,
 	}
 
+	equipo.MacAddress = core.NormalizeMac(equipo.MacAddress)
+	adaptadores := make([]AdapterInfo, len(equipo.Adaptadores))
+	for i, a := range equipo.Adaptadores {
+		a.MacAddress = core.NormalizeMac(a.MacAddress)
+		adaptadores[i] = a
+	}
+	equipo.Adaptadores = adaptadores
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	tx, err := db.BeginTx(ctx, nil)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("Error iniciando transaccion: %v", err)
 		return result, err
@@ -54,42 +245,59 @@ func CreateEquiposRepository(db *sql.DB, equipo EquipoInfo) (*EquipoResult, erro
 		}
 	}()
 
-	query := `INSERT INTO equipo_info 
-		(fecha_relevamiento, computer_name, nombre_anterior, mac_address, ip_address, piso, oficina)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`
-
-	execResult, err := tx.ExecContext(ctx, query,
-		equipo.FechaRelevamiento,
-		equipo.ComputerName,
-		equipo.NombreAnterior,
-		equipo.MacAddress,
-		equipo.IPAddress,
-		equipo.Piso,
-		equipo.Oficina,
-	)
+	existingID, existingName, err := buscarEquipoExistente(ctx, tx, equipo)
 	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("Error ejecutando INSERT: %v", err)
+		result.ErrorMessage = fmt.Sprintf("Error buscando equipo existente: %v", err)
 		return result, err
 	}
 
-	rowsAffected, err := execResult.RowsAffected()
-	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("Error obteniendo rows affected: %v", err)
-		return result, err
+	if existingID == 0 {
+		existingID, result.Created, err = s.upsertEquipo(ctx, tx, equipo)
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("Error guardando equipo: %v", err)
+			return result, err
+		}
+		result.RowsAffected = 1
+	} else {
+		if err := fusionarEquipoPorMac(ctx, tx, existingID, equipo.MacAddress); err != nil {
+			result.ErrorMessage = fmt.Sprintf("Error fusionando equipo repetido: %v", err)
+			return result, err
+		}
+
+		nombreAnterior := equipo.NombreAnterior
+		if existingName != "" && existingName != equipo.ComputerName {
+			nombreAnterior = existingName
+		}
+		columns := append([]string{"nombre_anterior"}, equipoColumns...)
+		values := append([]interface{}{nombreAnterior}, equipoValues(equipo)...)
+		values = append(values, existingID)
+		execResult, err := tx.ExecContext(ctx, updateQuery("equipo_info", columns)+` WHERE id = ?`, values...)
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("Error guardando equipo: %v", err)
+			return result, err
+		}
+
+		result.RowsAffected, err = execResult.RowsAffected()
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("Error obteniendo rows affected: %v", err)
+			return result, err
+		}
 	}
-	result.RowsAffected = rowsAffected
+	result.InsertedID = existingID
 
-	if rowsAffected == 0 {
-		result.ErrorMessage = "No se inserto ningun registro"
-		return result, fmt.Errorf("no se inserto ningun registro")
+	historialID, err := insertarHistorial(ctx, tx, existingID, equipo)
+	if err != nil {
+		result.ErrorMessage = fmt.Sprintf("Error guardando historial: %v", err)
+		return result, err
 	}
+	result.HistorialID = historialID
 
-	lastID, err := execResult.LastInsertId()
-	if err == nil {
-		result.InsertedID = lastID
+	if err := insertarAdaptadores(ctx, tx, existingID, historialID, equipo); err != nil {
+		result.ErrorMessage = fmt.Sprintf("Error guardando adaptadores: %v", err)
+		return result, err
 	}
 
-	verificado, err := verificarInsercion(ctx, tx, equipo)
+	verificado, err := verificarInsercion(ctx, tx, existingID)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("Error verificando insercion: %v", err)
 		return result, err
@@ -106,32 +314,304 @@ func CreateEquiposRepository(db *sql.DB, equipo EquipoInfo) (*EquipoResult, erro
 	return result, nil
 }
 
-func verificarInsercion(ctx context.Context, tx *sql.Tx, equipo EquipoInfo) (*EquipoVerificado, error) {
-	verificado := &EquipoVerificado{}
+func (s *sqlStore) GetByID(id int64) (*EquipoVerificado, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	verificado, err := scanEquipoVerificado(s.db.QueryRowContext(ctx, selectEquipoVerificado+` WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrEquipoNoEncontrado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error consultando equipo %d: %v", id, err)
+	}
+
+	return verificado, nil
+}
+
+// FindByMac busca por la MAC principal y por la de cualquier adaptador
+// guardado en equipo_adapter.
+func (s *sqlStore) FindByMac(macAddress string) ([]EquipoVerificado, error) {
+	macAddress = core.NormalizeMac(macAddress)
+	return s.query(selectEquipoVerificado+` WHERE mac_address = ?
+		OR id IN (SELECT equipo_id FROM equipo_adapter WHERE mac_address = ?)
+		ORDER BY id DESC`, macAddress, macAddress)
+}
 
-	verifyQuery := `SELECT id, computer_name, ip_address, mac_address, oficina, piso
-					FROM equipo_info 
-					WHERE computer_name = ? 
-					  AND mac_address = ? 
-					  AND fecha_relevamiento = ?
-					ORDER BY id DESC 
-					LIMIT 1`
+func (s *sqlStore) FindByComputerName(computerName string) ([]EquipoVerificado, error) {
+	return s.query(selectEquipoVerificado+` WHERE UPPER(computer_name) = UPPER(?) ORDER BY id DESC`, computerName)
+}
 
-	err := tx.QueryRowContext(ctx, verifyQuery,
-		equipo.ComputerName,
-		equipo.MacAddress,
-		equipo.FechaRelevamiento,
-	).Scan(
-		&verificado.ID,
-		&verificado.ComputerName,
-		&verificado.IPAddress,
-		&verificado.MacAddress,
-		&verificado.Oficina,
-		&verificado.Piso,
+func (s *sqlStore) ListByUbicacion(piso, oficina string) ([]EquipoVerificado, error) {
+	if oficina == "" {
+		return s.query(selectEquipoVerificado+` WHERE piso = ? ORDER BY id DESC`, piso)
+	}
+	return s.query(selectEquipoVerificado+` WHERE piso = ? AND oficina = ? ORDER BY id DESC`, piso, oficina)
+}
+
+// HasCaptura indica si la captura ya esta en el historial. El capture_id
+// tiene un indice unico, asi que dos capturas del mismo segundo no se
+// confunden.
+func (s *sqlStore) HasCaptura(captureID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM equipo_historial WHERE capture_id = ?`,
+		captureID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error consultando historial: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *sqlStore) ListHistorial(equipoID int64) ([]HistorialEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, equipo_id, COALESCE(capture_id, ''), fecha_relevamiento, computer_name,
+			mac_address, ip_address, piso, oficina, serial_number, memoria_ram_mb, modelo,
+			en_dominio, nombre_dominio
+		FROM equipo_historial
+		WHERE equipo_id = ?
+		ORDER BY id DESC`, equipoID)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando historial: %v", err)
+	}
+	defer rows.Close()
+
+	historial := []HistorialEntry{}
+	for rows.Next() {
+		var h HistorialEntry
+		if err := rows.Scan(&h.ID, &h.EquipoID, &h.CaptureID, &h.FechaRelevamiento, &h.ComputerName,
+			&h.MacAddress, &h.IPAddress, &h.Piso, &h.Oficina, &h.SerialNumber,
+			&h.MemoriaRAMMB, &h.Modelo, &h.EnDominio, &h.NombreDominio); err != nil {
+			return nil, fmt.Errorf("error leyendo historial: %v", err)
+		}
+		historial = append(historial, h)
+	}
+
+	return historial, rows.Err()
+}
+
+func (s *sqlStore) ResumenDominioPorPiso() ([]DominioResumen, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT piso,
+			COUNT(*),
+			SUM(CASE WHEN en_dominio = 1 THEN 1 ELSE 0 END),
+			SUM(CASE WHEN es_mec_local = 1 THEN 1 ELSE 0 END)
+		FROM equipo_info
+		GROUP BY piso
+		ORDER BY piso`)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando resumen de dominio: %v", err)
+	}
+	defer rows.Close()
+
+	resumen := []DominioResumen{}
+	for rows.Next() {
+		var r DominioResumen
+		if err := rows.Scan(&r.Piso, &r.Total, &r.EnDominio, &r.EsMecLocal); err != nil {
+			return nil, fmt.Errorf("error leyendo resumen de dominio: %v", err)
+		}
+		r.FueraDominio = r.Total - r.EnDominio
+		resumen = append(resumen, r)
+	}
+
+	return resumen, rows.Err()
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqlStore) query(query string, args ...interface{}) ([]EquipoVerificado, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando equipos: %v", err)
+	}
+	defer rows.Close()
+
+	equipos := []EquipoVerificado{}
+	for rows.Next() {
+		v, err := scanEquipoVerificado(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo equipo: %v", err)
+		}
+		equipos = append(equipos, *v)
+	}
+
+	return equipos, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEquipoVerificado(row rowScanner) (*EquipoVerificado, error) {
+	v := &EquipoVerificado{}
+	err := row.Scan(
+		&v.ID,
+		&v.ComputerName,
+		&v.IPAddress,
+		&v.MacAddress,
+		&v.Oficina,
+		&v.Piso,
+		&v.SerialNumber,
+		&v.SistemaOperativo,
+		&v.MemoriaRAMMB,
+		&v.Procesador,
+		&v.Fabricante,
+		&v.Modelo,
+		&v.BIOSVersion,
+		&v.EnDominio,
+		&v.NombreDominio,
+		&v.EsMecLocal,
+		&v.MacFabricante,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func insertQuery(table string, columns []string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders)
+}
+
+func updateQuery(table string, columns []string) string {
+	return fmt.Sprintf("UPDATE %s SET %s", table, strings.Join(columns, " = ?, ")+" = ?")
+}
+
+// buscarEquipoExistente identifica el equipo por numero de serie (si se
+// conoce) o por MAC, que ya viene en forma canonica. Devuelve id 0 si es la
+// primera vez que se releva.
+func buscarEquipoExistente(ctx context.Context, tx *sql.Tx, equipo EquipoInfo) (int64, string, error) {
+	var id int64
+	var computerName string
+
+	query := `SELECT id, computer_name FROM equipo_info
+		WHERE mac_address = ?
+		ORDER BY id DESC
+		LIMIT 1`
+	args := []interface{}{equipo.MacAddress}
+
+	if equipo.SerialNumber != "" {
+		query = `SELECT id, computer_name FROM equipo_info
+			WHERE mac_address = ? OR serial_number = ?
+			ORDER BY CASE WHEN serial_number = ? THEN 0 ELSE 1 END, id DESC
+			LIMIT 1`
+		args = append(args, equipo.SerialNumber, equipo.SerialNumber)
+	}
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&id, &computerName)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	return id, computerName, nil
+}
+
+// upsertEquipo inserta el equipo y, si los indices unicos de MAC o numero de
+// serie (migracion 0013) indican que otra captura simultanea ya lo creo,
+// actualiza esa fila. Devuelve el id y si la fila es nueva.
+func (s *sqlStore) upsertEquipo(ctx context.Context, tx *sql.Tx, equipo EquipoInfo) (int64, bool, error) {
+	columns := append([]string{"nombre_anterior"}, equipoColumns...)
+	values := append([]interface{}{equipo.NombreAnterior}, equipoValues(equipo)...)
+	query := insertQuery("equipo_info", columns)
+
+	if s.driver == DriverMySQL {
+		updates := []string{"nombre_anterior = IF(computer_name <> '' AND computer_name <> VALUES(computer_name), computer_name, VALUES(nombre_anterior))"}
+		for _, c := range equipoColumns {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", c, c))
+		}
+		updates = append(updates, "id = LAST_INSERT_ID(id)")
+
+		execResult, err := tx.ExecContext(ctx, query+" ON DUPLICATE KEY UPDATE "+strings.Join(updates, ", "), values...)
+		if err != nil {
+			return 0, false, err
+		}
+		id, err := execResult.LastInsertId()
+		if err != nil {
+			return 0, false, err
+		}
+		// MySQL informa 1 fila afectada al insertar y 2 al actualizar.
+		rowsAffected, err := execResult.RowsAffected()
+		if err != nil {
+			return 0, false, err
+		}
+		return id, rowsAffected == 1, nil
+	}
+
+	updates := []string{"nombre_anterior = CASE WHEN equipo_info.computer_name <> '' AND equipo_info.computer_name <> excluded.computer_name THEN equipo_info.computer_name ELSE excluded.nombre_anterior END"}
+	for _, c := range equipoColumns {
+		updates = append(updates, fmt.Sprintf("%s = excluded.%s", c, c))
+	}
+
+	// SQLite no distingue la insercion de la actualizacion. El conflicto solo
+	// aparece si otro proceso inserto el equipo despues de buscarlo (hay una
+	// sola conexion por proceso), y en ese caso se informa como nuevo.
+	var id int64
+	err := tx.QueryRowContext(ctx, query+" ON CONFLICT DO UPDATE SET "+strings.Join(updates, ", ")+" RETURNING id", values...).Scan(&id)
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// fusionarEquipoPorMac resuelve el caso en que el numero de serie identifica
+// un equipo y la MAC nueva ya esta en otra fila (por ejemplo, se cambio la
+// placa de red): esa fila se fusiona en la del equipo, igual que en la
+// migracion 0013, para no violar el indice unico de MAC.
+func fusionarEquipoPorMac(ctx context.Context, tx *sql.Tx, equipoID int64, macAddress string) error {
+	var otroID int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM equipo_info WHERE mac_address = ? AND id <> ?`,
+		macAddress, equipoID).Scan(&otroID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{
+		`UPDATE equipo_historial SET equipo_id = ? WHERE equipo_id = ?`,
+		`UPDATE equipo_adapter SET equipo_id = ? WHERE equipo_id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, equipoID, otroID); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM equipo_info WHERE id = ?`, otroID)
+	return err
+}
+
+func insertarHistorial(ctx context.Context, tx *sql.Tx, equipoID int64, equipo EquipoInfo) (int64, error) {
+	columns := append([]string{"equipo_id", "capture_id"}, equipoColumns...)
+	values := append([]interface{}{equipoID, nullIfEmpty(equipo.CaptureID)}, equipoValues(equipo)...)
+
+	execResult, err := tx.ExecContext(ctx, insertQuery("equipo_historial", columns), values...)
+	if err != nil {
+		return 0, err
+	}
+
+	return execResult.LastInsertId()
+}
 
+func verificarInsercion(ctx context.Context, tx *sql.Tx, id int64) (*EquipoVerificado, error) {
+	verificado, err := scanEquipoVerificado(tx.QueryRowContext(ctx, selectEquipoVerificado+` WHERE id = ?`, id))
 	if err != nil {
-		return nil, fmt.Errorf("no se pudo verificar el registro insertado: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrVerificacion, err)
 	}
 
 	return verificado, nil
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"relevamiento/core"
+	"testing"
+)
+
+// testStores devuelve los backends que se prueban con los mismos casos.
+func testStores(t *testing.T) map[string]EquipoStore {
+	t.Helper()
+
+	sqlite, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "equipos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	return map[string]EquipoStore{
+		DriverMemory: NewMemoryStore(),
+		DriverSQLite: sqlite,
+	}
+}
+
+func testEquipo(mac, serial, fecha string) EquipoInfo {
+	return EquipoInfo{
+		FechaRelevamiento: fecha,
+		ComputerName:      "PC-01",
+		NombreAnterior:    "PC-01",
+		MacAddress:        mac,
+		IPAddress:         "10.1.1.10",
+		Piso:              "1",
+		Oficina:           "Compras",
+		SerialNumber:      serial,
+	}
+}
+
+// TestCreateUpsert recorre capturas sucesivas del mismo parque: el numero de
+// serie identifica al equipo aunque cambie la placa de red y, sin serie, lo
+// identifica la MAC.
+func TestCreateUpsert(t *testing.T) {
+	steps := []struct {
+		name      string
+		equipo    EquipoInfo
+		created   bool
+		sameAs    int
+		historial int
+	}{
+		{"equipo nuevo", testEquipo("AA-BB-CC-00-00-01", "SN-1", "2024-03-01 10:00:00"), true, -1, 1},
+		{"misma MAC renombrado", renamed(testEquipo("aa:bb:cc:00:00:01", "SN-1", "2024-03-02 10:00:00"), "PC-01B"), false, 0, 2},
+		{"mismo serie con otra placa", testEquipo("AA-BB-CC-00-00-09", "SN-1", "2024-03-03 10:00:00"), false, 0, 3},
+		{"otro equipo", testEquipo("AA-BB-CC-00-00-02", "SN-2", "2024-03-03 11:00:00"), true, -1, 1},
+		{"sin serie por MAC", testEquipo("AA-BB-CC-00-00-02", "", "2024-03-04 11:00:00"), false, 3, 2},
+	}
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ids := make([]int64, len(steps))
+			for i, step := range steps {
+				result, err := store.Create(step.equipo)
+				if err != nil {
+					t.Fatalf("%s: %v", step.name, err)
+				}
+				ids[i] = result.InsertedID
+
+				if result.Created != step.created {
+					t.Errorf("%s: Created = %v, se esperaba %v", step.name, result.Created, step.created)
+				}
+				if step.sameAs >= 0 && ids[i] != ids[step.sameAs] {
+					t.Errorf("%s: ID = %d, se esperaba el del paso %d (%d)", step.name, ids[i], step.sameAs, ids[step.sameAs])
+				}
+				if result.VerifiedData == nil || result.VerifiedData.MacAddress != core.NormalizeMac(step.equipo.MacAddress) {
+					t.Errorf("%s: VerifiedData = %+v", step.name, result.VerifiedData)
+				}
+
+				historial, err := store.ListHistorial(ids[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(historial) != step.historial {
+					t.Errorf("%s: historial con %d entradas, se esperaban %d", step.name, len(historial), step.historial)
+				}
+			}
+
+			equipo, err := store.GetByID(ids[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if equipo.MacAddress != "AA-BB-CC-00-00-09" || equipo.ComputerName != "PC-01" {
+				t.Errorf("GetByID = %+v, se esperaba la ultima captura del equipo", equipo)
+			}
+			if _, err := store.GetByID(9999); err != ErrEquipoNoEncontrado {
+				t.Errorf("GetByID(9999) = %v, se esperaba ErrEquipoNoEncontrado", err)
+			}
+		})
+	}
+}
+
+func renamed(equipo EquipoInfo, computerName string) EquipoInfo {
+	equipo.ComputerName = computerName
+	return equipo
+}
+
+func TestFindByMacCanonical(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			equipo := testEquipo("aa:bb:cc:dd:ee:01", "", "2024-03-01 10:00:00")
+			equipo.CaptureID = "c0ffee"
+			equipo.Adaptadores = []AdapterInfo{
+				{Nombre: "Ethernet", MacAddress: "aa:bb:cc:dd:ee:01", EsPrincipal: true},
+				{Nombre: "Wi-Fi", MacAddress: "aa-bb-cc-dd-ee-02"},
+			}
+			if _, err := store.Create(equipo); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, mac := range []string{"AA-BB-CC-DD-EE-01", "aa:bb:cc:dd:ee:01", "aabb.ccdd.ee01", "AABBCCDDEE02"} {
+				found, err := store.FindByMac(mac)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(found) != 1 {
+					t.Errorf("FindByMac(%s) devolvio %d equipos, se esperaba 1", mac, len(found))
+				}
+			}
+
+			adapters, err := store.FindAdaptersByMac("aabb.ccdd.ee02")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(adapters) != 1 || adapters[0].MacAddress != "AA-BB-CC-DD-EE-02" {
+				t.Errorf("FindAdaptersByMac = %+v, se esperaba el adaptador en forma canonica", adapters)
+			}
+
+			ok, err := store.HasCaptura("c0ffee")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Error("HasCaptura no encontro la captura guardada")
+			}
+		})
+	}
+}
+
+// TestCaptureID comprueba que dos capturas del mismo equipo en el mismo
+// segundo son distintas y que una captura no se guarda dos veces.
+func TestCaptureID(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			primera := testEquipo("AA-BB-CC-00-00-01", "SN-1", "2024-03-01 10:00:00")
+			primera.CaptureID = "aaaa0001"
+			segunda := primera
+			segunda.CaptureID = "aaaa0002"
+
+			for _, equipo := range []EquipoInfo{primera, segunda} {
+				if _, err := store.Create(equipo); err != nil {
+					t.Fatalf("Create(%s): %v", equipo.CaptureID, err)
+				}
+			}
+			if _, err := store.Create(primera); err == nil {
+				t.Error("se guardo dos veces la misma captura")
+			}
+
+			for id, want := range map[string]bool{"aaaa0001": true, "aaaa0002": true, "aaaa0003": false} {
+				ok, err := store.HasCaptura(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok != want {
+					t.Errorf("HasCaptura(%s) = %v, se esperaba %v", id, ok, want)
+				}
+			}
+
+			equipos, err := store.FindByMac("AA-BB-CC-00-00-01")
+			if err != nil || len(equipos) != 1 {
+				t.Fatalf("FindByMac = %v, %v", equipos, err)
+			}
+			historial, err := store.ListHistorial(equipos[0].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(historial) != 2 || historial[0].CaptureID != "aaaa0002" {
+				t.Errorf("historial = %+v, se esperaban las dos capturas", historial)
+			}
+		})
+	}
+}
+
+// TestCreateFusionaMacRepetida cubre el equipo que se identifica por serie
+// con una MAC que ya tenia otra fila: las dos quedan en una sola.
+func TestCreateFusionaMacRepetida(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			sinSerie, err := store.Create(testEquipo("AA-BB-CC-00-00-01", "", "2024-03-01 10:00:00"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			conSerie, err := store.Create(testEquipo("AA-BB-CC-00-00-02", "SN-9", "2024-03-02 10:00:00"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := store.Create(testEquipo("AA-BB-CC-00-00-01", "SN-9", "2024-03-03 10:00:00"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Created || result.InsertedID != conSerie.InsertedID {
+				t.Fatalf("Create = %+v, se esperaba actualizar el equipo %d", result, conSerie.InsertedID)
+			}
+
+			found, err := store.FindByMac("AA-BB-CC-00-00-01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found) != 1 || found[0].ID != conSerie.InsertedID {
+				t.Errorf("FindByMac = %+v, se esperaba solo el equipo %d", found, conSerie.InsertedID)
+			}
+			if _, err := store.GetByID(sinSerie.InsertedID); err != ErrEquipoNoEncontrado {
+				t.Errorf("GetByID(%d) = %v, la fila repetida debia eliminarse", sinSerie.InsertedID, err)
+			}
+
+			historial, err := store.ListHistorial(conSerie.InsertedID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(historial) != 3 {
+				t.Errorf("historial con %d entradas, se esperaban 3", len(historial))
+			}
+		})
+	}
+}
+
+// TestUpsertEquipoConflict simula otra captura que inserto el mismo equipo
+// entre la busqueda y la insercion: el indice unico convierte la insercion
+// en una actualizacion.
+func TestUpsertEquipoConflict(t *testing.T) {
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "equipos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	s := store.(*sqlStore)
+
+	first, err := s.Create(testEquipo("AA-BB-CC-00-00-01", "", "2024-03-01 10:00:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	id, _, err := s.upsertEquipo(context.Background(), tx, renamed(testEquipo("AA-BB-CC-00-00-01", "", "2024-03-02 10:00:00"), "PC-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != first.InsertedID {
+		t.Errorf("upsertEquipo = %d, se esperaba el equipo %d", id, first.InsertedID)
+	}
+
+	var count int
+	var nombre, anterior string
+	if err := tx.QueryRow(`SELECT COUNT(*), MAX(computer_name), MAX(nombre_anterior) FROM equipo_info`).Scan(&count, &nombre, &anterior); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || nombre != "PC-02" || anterior != "PC-01" {
+		t.Errorf("equipo_info: %d filas, nombre %q, anterior %q", count, nombre, anterior)
+	}
+}
+
+// TestDatosRedDesconocidos guarda NULL cuando no se pudo determinar el DHCP
+// o la velocidad, en el equipo y en su historial.
+func TestDatosRedDesconocidos(t *testing.T) {
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "equipos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	s := store.(*sqlStore)
+
+	dhcp := false
+	conocido := testEquipo("AA-BB-CC-00-00-01", "SN-1", "2024-03-01 10:00:00")
+	conocido.DHCPHabilitado = &dhcp
+	conocido.VelocidadMbps = 100
+	desconocido := testEquipo("AA-BB-CC-00-00-02", "SN-2", "2024-03-01 10:00:00")
+
+	for _, equipo := range []EquipoInfo{conocido, desconocido} {
+		if _, err := s.Create(equipo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, table := range []string{"equipo_info", "equipo_historial"} {
+		rows, err := s.db.Query(`SELECT dhcp_habilitado, velocidad_mbps FROM ` + table + ` ORDER BY id`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for rows.Next() {
+			var dhcp, velocidad sql.NullInt64
+			if err := rows.Scan(&dhcp, &velocidad); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, fmt.Sprintf("%v/%v", dhcp, velocidad))
+		}
+		rows.Close()
+
+		want := []string{"{0 true}/{100 true}", "{0 false}/{0 false}"}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: %v, se esperaba %v", table, got, want)
+		}
+	}
+}
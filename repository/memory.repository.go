@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"fmt"
+	"relevamiento/core"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStore guarda los relevamientos en memoria. Se pierde al salir; sirve
+// para probar el flujo de captura sin base de datos.
+type MemoryStore struct {
+	mu              sync.Mutex
+	nextID          int64
+	nextHistorialID int64
+	equipos         []memoryEquipo
+	historial       []HistorialEntry
+	adaptadores     []EquipoAdapter
+}
+
+type memoryEquipo struct {
+	id     int64
+	equipo EquipoInfo
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextID: 1, nextHistorialID: 1}
+}
+
+func (s *MemoryStore) Create(equipo EquipoInfo) (*EquipoResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &EquipoResult{Success: true, RowsAffected: 1}
+
+	if equipo.CaptureID != "" {
+		for _, h := range s.historial {
+			if h.CaptureID == equipo.CaptureID {
+				return &EquipoResult{ErrorMessage: "captura duplicada"}, fmt.Errorf("la captura %s ya esta registrada", equipo.CaptureID)
+			}
+		}
+	}
+
+	equipo.MacAddress = core.NormalizeMac(equipo.MacAddress)
+	idx := s.findExisting(equipo)
+	if idx < 0 {
+		s.equipos = append(s.equipos, memoryEquipo{id: s.nextID, equipo: equipo})
+		s.nextID++
+		idx = len(s.equipos) - 1
+		result.Created = true
+	} else {
+		s.fusionarPorMac(idx, equipo.MacAddress)
+		idx = s.findExisting(equipo)
+		previo := s.equipos[idx].equipo
+		if previo.ComputerName != "" && previo.ComputerName != equipo.ComputerName {
+			equipo.NombreAnterior = previo.ComputerName
+		}
+		s.equipos[idx].equipo = equipo
+	}
+
+	id := s.equipos[idx].id
+	s.historial = append(s.historial, HistorialEntry{
+		ID:                s.nextHistorialID,
+		EquipoID:          id,
+		CaptureID:         equipo.CaptureID,
+		FechaRelevamiento: equipo.FechaRelevamiento,
+		ComputerName:      equipo.ComputerName,
+		MacAddress:        equipo.MacAddress,
+		IPAddress:         equipo.IPAddress,
+		Piso:              equipo.Piso,
+		Oficina:           equipo.Oficina,
+		SerialNumber:      equipo.SerialNumber,
+		MemoriaRAMMB:      equipo.MemoriaRAMMB,
+		Modelo:            equipo.Modelo,
+		EnDominio:         equipo.EnDominio,
+		NombreDominio:     equipo.NombreDominio,
+	})
+	result.HistorialID = s.nextHistorialID
+	s.nextHistorialID++
+
+	for _, a := range equipo.Adaptadores {
+		a.MacAddress = core.NormalizeMac(a.MacAddress)
+		s.adaptadores = append(s.adaptadores, EquipoAdapter{
+			ID:                int64(len(s.adaptadores) + 1),
+			EquipoID:          id,
+			HistorialID:       result.HistorialID,
+			FechaRelevamiento: equipo.FechaRelevamiento,
+			AdapterInfo:       a,
+		})
+	}
+
+	result.InsertedID = id
+	result.VerifiedData = toVerificado(id, equipo)
+	return result, nil
+}
+
+func (s *MemoryStore) GetByID(id int64) (*EquipoVerificado, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.equipos {
+		if e.id == id {
+			return toVerificado(e.id, e.equipo), nil
+		}
+	}
+	return nil, ErrEquipoNoEncontrado
+}
+
+func (s *MemoryStore) FindByMac(macAddress string) ([]EquipoVerificado, error) {
+	macAddress = core.NormalizeMac(macAddress)
+
+	s.mu.Lock()
+	ids := map[int64]bool{}
+	for _, a := range s.adaptadores {
+		if a.MacAddress == macAddress {
+			ids[a.EquipoID] = true
+		}
+	}
+	s.mu.Unlock()
+
+	return s.filterByID(func(id int64, e EquipoInfo) bool {
+		return e.MacAddress == macAddress || ids[id]
+	}), nil
+}
+
+func (s *MemoryStore) FindAdaptersByMac(macAddress string) ([]EquipoAdapter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	macAddress = core.NormalizeMac(macAddress)
+	adapters := []EquipoAdapter{}
+	for i := len(s.adaptadores) - 1; i >= 0; i-- {
+		if s.adaptadores[i].MacAddress == macAddress {
+			adapters = append(adapters, s.withComputerName(s.adaptadores[i]))
+		}
+	}
+	return adapters, nil
+}
+
+func (s *MemoryStore) ListAdaptadores(equipoID int64) ([]EquipoAdapter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ultimo int64
+	for _, a := range s.adaptadores {
+		if a.EquipoID == equipoID && a.HistorialID > ultimo {
+			ultimo = a.HistorialID
+		}
+	}
+
+	adapters := []EquipoAdapter{}
+	for _, a := range s.adaptadores {
+		if a.HistorialID == ultimo && a.EquipoID == equipoID {
+			adapters = append(adapters, s.withComputerName(a))
+		}
+	}
+	sort.SliceStable(adapters, func(i, j int) bool {
+		return adapters[i].EsPrincipal && !adapters[j].EsPrincipal
+	})
+	return adapters, nil
+}
+
+func (s *MemoryStore) FindByComputerName(computerName string) ([]EquipoVerificado, error) {
+	return s.filter(func(e EquipoInfo) bool {
+		return strings.EqualFold(e.ComputerName, computerName)
+	}), nil
+}
+
+func (s *MemoryStore) ListByUbicacion(piso, oficina string) ([]EquipoVerificado, error) {
+	return s.filter(func(e EquipoInfo) bool {
+		return e.Piso == piso && (oficina == "" || e.Oficina == oficina)
+	}), nil
+}
+
+func (s *MemoryStore) HasCaptura(captureID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, h := range s.historial {
+		if h.CaptureID != "" && h.CaptureID == captureID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *MemoryStore) ListHistorial(equipoID int64) ([]HistorialEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	historial := []HistorialEntry{}
+	for i := len(s.historial) - 1; i >= 0; i-- {
+		if s.historial[i].EquipoID == equipoID {
+			historial = append(historial, s.historial[i])
+		}
+	}
+	return historial, nil
+}
+
+func (s *MemoryStore) ResumenDominioPorPiso() ([]DominioResumen, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	porPiso := map[string]*DominioResumen{}
+	for _, e := range s.equipos {
+		r, ok := porPiso[e.equipo.Piso]
+		if !ok {
+			r = &DominioResumen{Piso: e.equipo.Piso}
+			porPiso[e.equipo.Piso] = r
+		}
+		r.Total++
+		if e.equipo.EnDominio {
+			r.EnDominio++
+		} else {
+			r.FueraDominio++
+		}
+		if e.equipo.EsMecLocal {
+			r.EsMecLocal++
+		}
+	}
+
+	resumen := make([]DominioResumen, 0, len(porPiso))
+	for _, r := range porPiso {
+		resumen = append(resumen, *r)
+	}
+	sort.Slice(resumen, func(i, j int) bool {
+		return resumen[i].Piso < resumen[j].Piso
+	})
+	return resumen, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// findExisting replica buscarEquipoExistente: el numero de serie tiene
+// prioridad sobre la MAC.
+func (s *MemoryStore) findExisting(equipo EquipoInfo) int {
+	if equipo.SerialNumber != "" {
+		for i := len(s.equipos) - 1; i >= 0; i-- {
+			if s.equipos[i].equipo.SerialNumber == equipo.SerialNumber {
+				return i
+			}
+		}
+	}
+	for i := len(s.equipos) - 1; i >= 0; i-- {
+		if s.equipos[i].equipo.MacAddress == equipo.MacAddress {
+			return i
+		}
+	}
+	return -1
+}
+
+// fusionarPorMac pasa al equipo idx el historial y los adaptadores de otro
+// equipo con la misma MAC y lo elimina, como fusionarEquipoPorMac.
+func (s *MemoryStore) fusionarPorMac(idx int, macAddress string) {
+	id := s.equipos[idx].id
+	for i, e := range s.equipos {
+		if i == idx || e.equipo.MacAddress != macAddress {
+			continue
+		}
+		for j := range s.historial {
+			if s.historial[j].EquipoID == e.id {
+				s.historial[j].EquipoID = id
+			}
+		}
+		for j := range s.adaptadores {
+			if s.adaptadores[j].EquipoID == e.id {
+				s.adaptadores[j].EquipoID = id
+			}
+		}
+		s.equipos = append(s.equipos[:i], s.equipos[i+1:]...)
+		return
+	}
+}
+
+func (s *MemoryStore) filter(match func(EquipoInfo) bool) []EquipoVerificado {
+	return s.filterByID(func(_ int64, e EquipoInfo) bool {
+		return match(e)
+	})
+}
+
+func (s *MemoryStore) filterByID(match func(int64, EquipoInfo) bool) []EquipoVerificado {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []EquipoVerificado{}
+	for i := len(s.equipos) - 1; i >= 0; i-- {
+		e := s.equipos[i]
+		if match(e.id, e.equipo) {
+			result = append(result, *toVerificado(e.id, e.equipo))
+		}
+	}
+	return result
+}
+
+// withComputerName completa el nombre actual del equipo, como el JOIN de
+// selectEquipoAdapter.
+func (s *MemoryStore) withComputerName(a EquipoAdapter) EquipoAdapter {
+	for _, e := range s.equipos {
+		if e.id == a.EquipoID {
+			a.ComputerName = e.equipo.ComputerName
+			break
+		}
+	}
+	return a
+}
+
+func toVerificado(id int64, equipo EquipoInfo) *EquipoVerificado {
+	return &EquipoVerificado{
+		ID:               id,
+		ComputerName:     equipo.ComputerName,
+		IPAddress:        equipo.IPAddress,
+		MacAddress:       equipo.MacAddress,
+		MacFabricante:    equipo.MacFabricante,
+		Oficina:          equipo.Oficina,
+		Piso:             equipo.Piso,
+		SerialNumber:     equipo.SerialNumber,
+		SistemaOperativo: equipo.SistemaOperativo,
+		MemoriaRAMMB:     equipo.MemoriaRAMMB,
+		Procesador:       equipo.Procesador,
+		Fabricante:       equipo.Fabricante,
+		Modelo:           equipo.Modelo,
+		BIOSVersion:      equipo.BIOSVersion,
+		EnDominio:        equipo.EnDominio,
+		NombreDominio:    equipo.NombreDominio,
+		EsMecLocal:       equipo.EsMecLocal,
+	}
+}
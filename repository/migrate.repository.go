@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER NOT NULL PRIMARY KEY,
+	name       VARCHAR(255) NOT NULL,
+	applied_at VARCHAR(19) NOT NULL
+)`
+
+// createSchemaMigrationSteps guarda las sentencias ya ejecutadas de una
+// migracion de MySQL que no termino (ver runMigration).
+const createSchemaMigrationSteps = `CREATE TABLE IF NOT EXISTS schema_migration_steps (
+	version   INTEGER NOT NULL,
+	direction VARCHAR(4) NOT NULL,
+	step      INTEGER NOT NULL,
+	PRIMARY KEY (version, direction, step)
+)`
+
+// LoadMigrations lee las migraciones embebidas del dialecto indicado,
+// ordenadas por version. Los archivos se llaman NNNN_nombre.up.sql y
+// NNNN_nombre.down.sql.
+func LoadMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no hay migraciones para el driver %s", driver)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		name := e.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("nombre de migracion invalido: %s", name)
+		}
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("version de migracion invalida: %s", name)
+		}
+
+		data, err := migrationsFS.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo migracion %s: %v", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migracion %04d_%s sin archivo up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrateUp aplica en orden todas las migraciones pendientes y devuelve las
+// que se aplicaron.
+func MigrateUp(db *sql.DB, driver string) ([]Migration, error) {
+	migrations, applied, err := prepareMigrations(db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		record := func(ex execer) error {
+			_, err := ex.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+				m.Version, m.Name, time.Now().Format("2006-01-02 15:04:05"))
+			return err
+		}
+		if err := runMigration(db, driver, m, directionUp, m.Up, record); err != nil {
+			return done, fmt.Errorf("error aplicando migracion %04d_%s: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// MigrateDown revierte las ultimas steps migraciones aplicadas. Las que no
+// tienen archivo down no se revierten: 0001 adopta una equipo_info que
+// puede ser anterior a esta herramienta y borrarla perderia esos datos.
+func MigrateDown(db *sql.DB, driver string, steps int) ([]Migration, error) {
+	migrations, applied, err := prepareMigrations(db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return done, fmt.Errorf("la migracion %04d_%s no se puede revertir", m.Version, m.Name)
+		}
+
+		record := func(ex execer) error {
+			_, err := ex.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version)
+			return err
+		}
+		if err := runMigration(db, driver, m, directionDown, m.Down, record); err != nil {
+			return done, fmt.Errorf("error revirtiendo migracion %04d_%s: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+func GetMigrationStatus(db *sql.DB, driver string) ([]MigrationStatus, error) {
+	migrations, applied, err := prepareMigrations(db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		status = append(status, MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return status, nil
+}
+
+func prepareMigrations(db *sql.DB, driver string) ([]Migration, map[int]string, error) {
+	migrations, err := LoadMigrations(driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := db.Exec(createSchemaMigrations); err != nil {
+		return nil, nil, fmt.Errorf("error creando schema_migrations: %v", err)
+	}
+	if driver == DriverMySQL {
+		if _, err := db.Exec(createSchemaMigrationSteps); err != nil {
+			return nil, nil, fmt.Errorf("error creando schema_migration_steps: %v", err)
+		}
+	}
+
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error leyendo schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]string{}
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, nil, fmt.Errorf("error leyendo schema_migrations: %v", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return migrations, applied, rows.Err()
+}
+
+const (
+	directionUp   = "up"
+	directionDown = "down"
+)
+
+// execer es lo comun a *sql.DB y *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// runMigration ejecuta el script de una migracion y la registra con record.
+// En SQLite todo va en una transaccion: si una sentencia falla no queda
+// nada aplicado. En MySQL cada sentencia DDL confirma sola, asi que se
+// anota en schema_migration_steps cada sentencia ejecutada y, si la
+// migracion se corta, el siguiente intento retoma desde la que fallo.
+func runMigration(db *sql.DB, driver string, m Migration, direction, script string, record func(execer) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	statements := splitStatements(script)
+
+	if driver != DriverMySQL {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("%v\n%s", err, stmt)
+			}
+		}
+		if err := record(tx); err != nil {
+			return fmt.Errorf("error registrando migracion: %v", err)
+		}
+		return tx.Commit()
+	}
+
+	done, err := migrationSteps(db, m.Version, direction)
+	if err != nil {
+		return err
+	}
+
+	for i, stmt := range statements {
+		if done[i] {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("sentencia %d de %d: %v\n%s", i+1, len(statements), err, stmt)
+		}
+		if _, err := db.ExecContext(ctx, `INSERT INTO schema_migration_steps (version, direction, step) VALUES (?, ?, ?)`,
+			m.Version, direction, i); err != nil {
+			return fmt.Errorf("error registrando sentencia %d: %v", i+1, err)
+		}
+	}
+
+	if err := record(db); err != nil {
+		return fmt.Errorf("error registrando migracion: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `DELETE FROM schema_migration_steps WHERE version = ?`, m.Version)
+	return err
+}
+
+// migrationSteps devuelve las sentencias ya ejecutadas de una migracion que
+// quedo a medias.
+func migrationSteps(db *sql.DB, version int, direction string) (map[int]bool, error) {
+	rows, err := db.Query(`SELECT step FROM schema_migration_steps WHERE version = ? AND direction = ?`, version, direction)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo schema_migration_steps: %v", err)
+	}
+	defer rows.Close()
+
+	done := map[int]bool{}
+	for rows.Next() {
+		var step int
+		if err := rows.Scan(&step); err != nil {
+			return nil, fmt.Errorf("error leyendo schema_migration_steps: %v", err)
+		}
+		done[step] = true
+	}
+	return done, rows.Err()
+}
+
+// splitStatements separa un script en sentencias, ya que el driver de MySQL
+// no acepta varias por Exec sin multiStatements. Solo corta en los ";" que
+// no estan dentro de comillas o de un comentario; las migraciones no usan
+// triggers ni procedimientos.
+func splitStatements(script string) []string {
+	statements := []string{}
+	var current strings.Builder
+
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(script) {
+				if script[end] == '\\' && c != '`' {
+					end += 2
+					continue
+				}
+				if script[end] == c {
+					if end+1 < len(script) && script[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(script) {
+				end = len(script) - 1
+			}
+			current.WriteString(script[i : end+1])
+			i = end
+			continue
+
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end
+				current.WriteByte('\n')
+			}
+			continue
+
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script)
+			} else {
+				end += i + 4
+			}
+			current.WriteByte(' ')
+			i = end - 1
+			continue
+
+		case c == ';':
+			flush()
+			continue
+		}
+
+		current.WriteByte(c)
+	}
+	flush()
+
+	return statements
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simples",
+			script: "CREATE TABLE a (x INT);\nCREATE TABLE b (y INT);\n",
+			want:   []string{"CREATE TABLE a (x INT)", "CREATE TABLE b (y INT)"},
+		},
+		{
+			name:   "punto y coma en literal",
+			script: "INSERT INTO a VALUES ('x;y');INSERT INTO a VALUES ('it''s;')",
+			want:   []string{"INSERT INTO a VALUES ('x;y')", "INSERT INTO a VALUES ('it''s;')"},
+		},
+		{
+			name:   "comentarios",
+			script: "-- crea a; y b\nCREATE TABLE a (x INT); /* ; */ CREATE TABLE b (y INT);",
+			want:   []string{"CREATE TABLE a (x INT)", "CREATE TABLE b (y INT)"},
+		},
+		{
+			name:   "comillas dobles y backticks",
+			script: "UPDATE a SET `x;y` = \"1;2\";\nDELETE FROM a",
+			want:   []string{"UPDATE a SET `x;y` = \"1;2\"", "DELETE FROM a"},
+		},
+		{
+			name:   "comentario al final sin salto de linea",
+			script: "DELETE FROM a; -- nada mas; fin",
+			want:   []string{"DELETE FROM a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateUpDownSQLite(t *testing.T) {
+	db := openTestSQLite(t)
+
+	migrations, err := LoadMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := MigrateUp(db, DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("se aplicaron %d migraciones, se esperaban %d", len(applied), len(migrations))
+	}
+
+	reverted, err := MigrateDown(db, DriverSQLite, len(migrations)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != len(migrations)-1 {
+		t.Fatalf("se revirtieron %d migraciones, se esperaban %d", len(reverted), len(migrations)-1)
+	}
+
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatalf("no se pudo volver a aplicar: %v", err)
+	}
+}
+
+// 0001 adopta una equipo_info existente y no debe borrarse nunca.
+func TestMigrateDownKeepsBaseline(t *testing.T) {
+	db := openTestSQLite(t)
+
+	if _, err := db.Exec(`CREATE TABLE equipo_info (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		fecha_relevamiento TEXT NOT NULL,
+		computer_name TEXT NOT NULL,
+		nombre_anterior TEXT,
+		mac_address TEXT NOT NULL,
+		ip_address TEXT,
+		piso TEXT,
+		oficina TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO equipo_info (fecha_relevamiento, computer_name, mac_address)
+		VALUES ('2020-01-01 00:00:00', 'PC-LEGADO', 'AA-BB-CC-DD-EE-FF')`); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := LoadMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := MigrateDown(db, DriverSQLite, len(migrations))
+	if err == nil {
+		t.Fatal("revertir 0001 debe fallar")
+	}
+	if len(reverted) != len(migrations)-1 {
+		t.Errorf("se revirtieron %d migraciones, se esperaban %d", len(reverted), len(migrations)-1)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM equipo_info`).Scan(&count); err != nil {
+		t.Fatalf("equipo_info ya no existe: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("equipo_info tiene %d filas, se esperaba 1", count)
+	}
+}
+
+// Una migracion que falla a mitad de camino no deja nada aplicado.
+func TestRunMigrationRollsBackSQLite(t *testing.T) {
+	db := openTestSQLite(t)
+	if _, _, err := prepareMigrations(db, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Migration{Version: 999, Name: "falla"}
+	script := "CREATE TABLE parcial (x INTEGER); INSERT INTO no_existe VALUES (1);"
+	record := func(ex execer) error {
+		_, err := ex.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.Version, m.Name, "2024-01-01 00:00:00")
+		return err
+	}
+
+	err := runMigration(db, DriverSQLite, m, directionUp, script, record)
+	if err == nil || !strings.Contains(err.Error(), "no_existe") {
+		t.Fatalf("se esperaba el error de la segunda sentencia, se obtuvo %v", err)
+	}
+
+	var name string
+	err = db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'parcial'`).Scan(&name)
+	if err != sql.ErrNoRows {
+		t.Errorf("la tabla parcial quedo creada (err=%v)", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = 999`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("la migracion fallida quedo registrada")
+	}
+}
+
+func TestMacCanonicaMigration(t *testing.T) {
+	db := openTestSQLite(t)
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	macs := map[string]string{
+		"aabb.ccdd.ee01":    "AA-BB-CC-DD-EE-01",
+		"AABBCCDDEE02":      "AA-BB-CC-DD-EE-02",
+		" aabbccddee03 ":    "AA-BB-CC-DD-EE-03",
+		"AA-BB-CC-DD-EE-04": "AA-BB-CC-DD-EE-04",
+		"zzzzzzzzzzzz":      "zzzzzzzzzzzz",
+		"sin.mac.valida":    "sin.mac.valida",
+	}
+	for raw := range macs {
+		if _, err := db.Exec(`INSERT INTO equipo_info (fecha_relevamiento, computer_name, mac_address)
+			VALUES ('2024-01-01 00:00:00', 'PC', ?)`, raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrations, err := LoadMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range migrations {
+		if m.Name != "mac_canonica" {
+			continue
+		}
+		for _, stmt := range splitStatements(m.Up) {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("%v\n%s", err, stmt)
+			}
+		}
+	}
+
+	rows, err := db.Query(`SELECT mac_address FROM equipo_info`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	got := map[string]bool{}
+	for rows.Next() {
+		var mac string
+		if err := rows.Scan(&mac); err != nil {
+			t.Fatal(err)
+		}
+		got[mac] = true
+	}
+	for raw, want := range macs {
+		if !got[want] {
+			t.Errorf("%q deberia quedar como %q; hay %v", raw, want, got)
+		}
+	}
+}
+
+func TestEquipoUnicoMigration(t *testing.T) {
+	db := openTestSQLite(t)
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := LoadMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := 0
+	for _, m := range migrations {
+		if m.Version >= 13 {
+			pending++
+		}
+	}
+	if _, err := MigrateDown(db, DriverSQLite, pending); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []struct {
+		fecha, mac, serial string
+	}{
+		{"2024-01-01 10:00:00", "AA-BB-CC-00-00-01", "SN-1"},
+		{"2024-02-01 10:00:00", "AA-BB-CC-00-00-09", "SN-1"},
+		{"2024-01-01 10:00:00", "AA-BB-CC-00-00-02", ""},
+		{"2024-03-01 10:00:00", "AA-BB-CC-00-00-02", ""},
+		{"2024-01-01 10:00:00", "AA-BB-CC-00-00-03", "To be filled by O.E.M."},
+		{"2024-01-01 10:00:00", "AA-BB-CC-00-00-04", "To be filled by O.E.M."},
+	}
+	for _, r := range rows {
+		res, err := db.Exec(`INSERT INTO equipo_info (fecha_relevamiento, computer_name, mac_address, serial_number)
+			VALUES (?, 'PC', ?, ?)`, r.fecha, r.mac, r.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := res.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO equipo_historial (equipo_id, fecha_relevamiento, computer_name, mac_address, serial_number)
+			VALUES (?, ?, 'PC', ?, ?)`, id, r.fecha, r.mac, r.serial); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	var equipos, huerfanos int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM equipo_info`).Scan(&equipos); err != nil {
+		t.Fatal(err)
+	}
+	if equipos != 4 {
+		t.Errorf("quedaron %d equipos, se esperaban 4", equipos)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM equipo_historial
+		WHERE equipo_id NOT IN (SELECT id FROM equipo_info)`).Scan(&huerfanos); err != nil {
+		t.Fatal(err)
+	}
+	if huerfanos != 0 {
+		t.Errorf("%d entradas de historial apuntan a equipos eliminados", huerfanos)
+	}
+
+	var mac string
+	if err := db.QueryRow(`SELECT mac_address FROM equipo_info WHERE serial_number = 'SN-1'`).Scan(&mac); err != nil {
+		t.Fatal(err)
+	}
+	if mac != "AA-BB-CC-00-00-09" {
+		t.Errorf("SN-1 quedo con %s, se esperaba la fila mas reciente", mac)
+	}
+
+	if _, err := db.Exec(`INSERT INTO equipo_info (fecha_relevamiento, computer_name, mac_address)
+		VALUES ('2024-04-01 10:00:00', 'PC', 'AA-BB-CC-00-00-02')`); err == nil {
+		t.Error("el indice unico debia rechazar una MAC repetida")
+	}
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS equipo_info (
+    id                 INT AUTO_INCREMENT PRIMARY KEY,
+    fecha_relevamiento DATETIME NOT NULL,
+    computer_name      VARCHAR(255) NOT NULL,
+    nombre_anterior    VARCHAR(255),
+    mac_address        VARCHAR(17) NOT NULL,
+    ip_address         VARCHAR(45),
+    piso               VARCHAR(50),
+    oficina            VARCHAR(255)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS equipo_historial;
+DROP INDEX idx_equipo_info_serial ON equipo_info;
+DROP INDEX idx_equipo_info_mac ON equipo_info;
+ALTER TABLE equipo_info DROP COLUMN serial_number;
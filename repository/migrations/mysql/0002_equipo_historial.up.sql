@@ -0,0 +1,17 @@
+ALTER TABLE equipo_info ADD COLUMN serial_number VARCHAR(100) NOT NULL DEFAULT '';
+CREATE INDEX idx_equipo_info_mac ON equipo_info (mac_address);
+CREATE INDEX idx_equipo_info_serial ON equipo_info (serial_number);
+CREATE TABLE IF NOT EXISTS equipo_historial (
+    id                 INT AUTO_INCREMENT PRIMARY KEY,
+    equipo_id          INT NOT NULL,
+    fecha_relevamiento DATETIME NOT NULL,
+    computer_name      VARCHAR(255) NOT NULL,
+    mac_address        VARCHAR(17) NOT NULL,
+    ip_address         VARCHAR(45),
+    piso               VARCHAR(50),
+    oficina            VARCHAR(255),
+    serial_number      VARCHAR(100) NOT NULL DEFAULT '',
+    INDEX idx_equipo_historial_equipo (equipo_id),
+    INDEX idx_equipo_historial_mac_fecha (mac_address, fecha_relevamiento),
+    CONSTRAINT fk_equipo_historial_equipo FOREIGN KEY (equipo_id) REFERENCES equipo_info (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,21 @@
+DROP INDEX idx_equipo_info_modelo ON equipo_info;
+ALTER TABLE equipo_historial DROP COLUMN bios_version;
+ALTER TABLE equipo_historial DROP COLUMN modelo;
+ALTER TABLE equipo_historial DROP COLUMN fabricante;
+ALTER TABLE equipo_historial DROP COLUMN usuario_actual;
+ALTER TABLE equipo_historial DROP COLUMN procesador;
+ALTER TABLE equipo_historial DROP COLUMN memoria_ram_mb;
+ALTER TABLE equipo_historial DROP COLUMN memoria_ram;
+ALTER TABLE equipo_historial DROP COLUMN arquitectura;
+ALTER TABLE equipo_historial DROP COLUMN version_so;
+ALTER TABLE equipo_historial DROP COLUMN sistema_operativo;
+ALTER TABLE equipo_info DROP COLUMN bios_version;
+ALTER TABLE equipo_info DROP COLUMN modelo;
+ALTER TABLE equipo_info DROP COLUMN fabricante;
+ALTER TABLE equipo_info DROP COLUMN usuario_actual;
+ALTER TABLE equipo_info DROP COLUMN procesador;
+ALTER TABLE equipo_info DROP COLUMN memoria_ram_mb;
+ALTER TABLE equipo_info DROP COLUMN memoria_ram;
+ALTER TABLE equipo_info DROP COLUMN arquitectura;
+ALTER TABLE equipo_info DROP COLUMN version_so;
+ALTER TABLE equipo_info DROP COLUMN sistema_operativo;
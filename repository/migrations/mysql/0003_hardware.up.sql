@@ -0,0 +1,21 @@
+ALTER TABLE equipo_info ADD COLUMN sistema_operativo VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN version_so VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN arquitectura VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN memoria_ram VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN memoria_ram_mb INT NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN procesador VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN usuario_actual VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN fabricante VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN modelo VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN bios_version VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN sistema_operativo VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN version_so VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN arquitectura VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN memoria_ram VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN memoria_ram_mb INT NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN procesador VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN usuario_actual VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN fabricante VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN modelo VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN bios_version VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX idx_equipo_info_modelo ON equipo_info (modelo);
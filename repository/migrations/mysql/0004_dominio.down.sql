@@ -0,0 +1,7 @@
+DROP INDEX idx_equipo_info_piso_dominio ON equipo_info;
+ALTER TABLE equipo_historial DROP COLUMN es_mec_local;
+ALTER TABLE equipo_historial DROP COLUMN nombre_dominio;
+ALTER TABLE equipo_historial DROP COLUMN en_dominio;
+ALTER TABLE equipo_info DROP COLUMN es_mec_local;
+ALTER TABLE equipo_info DROP COLUMN nombre_dominio;
+ALTER TABLE equipo_info DROP COLUMN en_dominio;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS equipo_adapter (
+    id                 INT AUTO_INCREMENT PRIMARY KEY,
+    equipo_id          INT NOT NULL,
+    historial_id       INT NOT NULL,
+    fecha_relevamiento DATETIME NOT NULL,
+    nombre             VARCHAR(255) NOT NULL,
+    tipo               VARCHAR(255) NOT NULL DEFAULT '',
+    mac_address        VARCHAR(17) NOT NULL,
+    estado             VARCHAR(100) NOT NULL DEFAULT '',
+    es_ethernet        TINYINT(1) NOT NULL DEFAULT 0,
+    es_activo          TINYINT(1) NOT NULL DEFAULT 0,
+    es_principal       TINYINT(1) NOT NULL DEFAULT 0,
+    INDEX idx_equipo_adapter_mac (mac_address),
+    INDEX idx_equipo_adapter_equipo (equipo_id),
+    INDEX idx_equipo_adapter_historial (historial_id),
+    CONSTRAINT fk_equipo_adapter_equipo FOREIGN KEY (equipo_id) REFERENCES equipo_info (id),
+    CONSTRAINT fk_equipo_adapter_historial FOREIGN KEY (historial_id) REFERENCES equipo_historial (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
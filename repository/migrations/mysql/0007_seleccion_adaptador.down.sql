@@ -0,0 +1,4 @@
+ALTER TABLE equipo_historial DROP COLUMN motivo_seleccion;
+ALTER TABLE equipo_historial DROP COLUMN adaptador_principal;
+ALTER TABLE equipo_info DROP COLUMN motivo_seleccion;
+ALTER TABLE equipo_info DROP COLUMN adaptador_principal;
@@ -0,0 +1,2 @@
+ALTER TABLE equipo_info ADD COLUMN rango_red VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN rango_red VARCHAR(255) NOT NULL DEFAULT '';
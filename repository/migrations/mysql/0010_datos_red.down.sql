@@ -0,0 +1,16 @@
+ALTER TABLE equipo_historial DROP COLUMN duplex;
+ALTER TABLE equipo_historial DROP COLUMN velocidad_mbps;
+ALTER TABLE equipo_historial DROP COLUMN lease_expira;
+ALTER TABLE equipo_historial DROP COLUMN lease_obtenido;
+ALTER TABLE equipo_historial DROP COLUMN servidor_dhcp;
+ALTER TABLE equipo_historial DROP COLUMN dhcp_habilitado;
+ALTER TABLE equipo_historial DROP COLUMN servidores_dns;
+ALTER TABLE equipo_historial DROP COLUMN gateway;
+ALTER TABLE equipo_info DROP COLUMN duplex;
+ALTER TABLE equipo_info DROP COLUMN velocidad_mbps;
+ALTER TABLE equipo_info DROP COLUMN lease_expira;
+ALTER TABLE equipo_info DROP COLUMN lease_obtenido;
+ALTER TABLE equipo_info DROP COLUMN servidor_dhcp;
+ALTER TABLE equipo_info DROP COLUMN dhcp_habilitado;
+ALTER TABLE equipo_info DROP COLUMN servidores_dns;
+ALTER TABLE equipo_info DROP COLUMN gateway;
@@ -0,0 +1,16 @@
+ALTER TABLE equipo_info ADD COLUMN gateway VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN servidores_dns VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN dhcp_habilitado TINYINT(1) NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN servidor_dhcp VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN lease_obtenido DATETIME NULL;
+ALTER TABLE equipo_info ADD COLUMN lease_expira DATETIME NULL;
+ALTER TABLE equipo_info ADD COLUMN velocidad_mbps INT NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN duplex VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN gateway VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN servidores_dns VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN dhcp_habilitado TINYINT(1) NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN servidor_dhcp VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN lease_obtenido DATETIME NULL;
+ALTER TABLE equipo_historial ADD COLUMN lease_expira DATETIME NULL;
+ALTER TABLE equipo_historial ADD COLUMN velocidad_mbps INT NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN duplex VARCHAR(10) NOT NULL DEFAULT '';
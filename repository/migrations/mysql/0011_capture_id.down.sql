@@ -0,0 +1,2 @@
+DROP INDEX ux_equipo_historial_capture ON equipo_historial;
+ALTER TABLE equipo_historial DROP COLUMN capture_id;
@@ -0,0 +1,2 @@
+ALTER TABLE equipo_historial ADD COLUMN capture_id VARCHAR(32) NULL;
+CREATE UNIQUE INDEX ux_equipo_historial_capture ON equipo_historial (capture_id);
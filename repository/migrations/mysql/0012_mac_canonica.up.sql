@@ -0,0 +1,34 @@
+-- 0006 solo unifico las formas con ':' y '-'. Esta lleva aabb.ccdd.eeff y
+-- AABBCCDDEEFF a AA-BB-CC-DD-EE-FF, como core.ParseMAC.
+UPDATE equipo_info SET mac_address = UPPER(CONCAT_WS('-',
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 1, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 3, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 5, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 7, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 9, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 11, 2)))
+WHERE (CHAR_LENGTH(TRIM(mac_address)) = 12
+       OR (CHAR_LENGTH(TRIM(mac_address)) = 14 AND SUBSTRING(TRIM(mac_address), 5, 1) = '.' AND SUBSTRING(TRIM(mac_address), 10, 1) = '.'))
+  AND REPLACE(TRIM(mac_address), '.', '') REGEXP '^[0-9A-Fa-f]{12}$';
+
+UPDATE equipo_historial SET mac_address = UPPER(CONCAT_WS('-',
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 1, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 3, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 5, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 7, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 9, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 11, 2)))
+WHERE (CHAR_LENGTH(TRIM(mac_address)) = 12
+       OR (CHAR_LENGTH(TRIM(mac_address)) = 14 AND SUBSTRING(TRIM(mac_address), 5, 1) = '.' AND SUBSTRING(TRIM(mac_address), 10, 1) = '.'))
+  AND REPLACE(TRIM(mac_address), '.', '') REGEXP '^[0-9A-Fa-f]{12}$';
+
+UPDATE equipo_adapter SET mac_address = UPPER(CONCAT_WS('-',
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 1, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 3, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 5, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 7, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 9, 2),
+    SUBSTRING(REPLACE(TRIM(mac_address), '.', ''), 11, 2)))
+WHERE (CHAR_LENGTH(TRIM(mac_address)) = 12
+       OR (CHAR_LENGTH(TRIM(mac_address)) = 14 AND SUBSTRING(TRIM(mac_address), 5, 1) = '.' AND SUBSTRING(TRIM(mac_address), 10, 1) = '.'))
+  AND REPLACE(TRIM(mac_address), '.', '') REGEXP '^[0-9A-Fa-f]{12}$';
@@ -0,0 +1,5 @@
+-- Las filas fusionadas no se recuperan; solo se vuelve a los indices comunes.
+DROP INDEX ux_equipo_info_serial ON equipo_info;
+ALTER TABLE equipo_info DROP COLUMN serial_unico;
+DROP INDEX ux_equipo_info_mac ON equipo_info;
+CREATE INDEX idx_equipo_info_mac ON equipo_info (mac_address);
@@ -0,0 +1,32 @@
+-- Deja una fila por equipo: las filas repetidas por numero de serie y despues
+-- por MAC se fusionan en la del relevamiento mas reciente, que se queda con
+-- el historial y los adaptadores de las demas.
+UPDATE equipo_info SET serial_number = ''
+WHERE LOWER(TRIM(serial_number)) IN ('0', 'none', 'n/a', 'default string', 'to be filled by o.e.m.', 'system serial number', 'not specified', 'not applicable', '123456789')
+   OR TRIM(BOTH '0' FROM TRIM(serial_number)) = '';
+CREATE TABLE equipo_info_fusion (id_anterior INT NOT NULL PRIMARY KEY, id_nuevo INT NOT NULL) ENGINE=InnoDB;
+INSERT INTO equipo_info_fusion (id_anterior, id_nuevo)
+SELECT o.id, (SELECT n.id FROM equipo_info n WHERE n.serial_number = o.serial_number ORDER BY n.fecha_relevamiento DESC, n.id DESC LIMIT 1)
+FROM equipo_info o
+WHERE o.serial_number <> '' AND o.id <> (SELECT n.id FROM equipo_info n WHERE n.serial_number = o.serial_number ORDER BY n.fecha_relevamiento DESC, n.id DESC LIMIT 1);
+UPDATE equipo_historial SET equipo_id = (SELECT id_nuevo FROM equipo_info_fusion WHERE id_anterior = equipo_historial.equipo_id)
+WHERE equipo_id IN (SELECT id_anterior FROM equipo_info_fusion);
+UPDATE equipo_adapter SET equipo_id = (SELECT id_nuevo FROM equipo_info_fusion WHERE id_anterior = equipo_adapter.equipo_id)
+WHERE equipo_id IN (SELECT id_anterior FROM equipo_info_fusion);
+DELETE FROM equipo_info WHERE id IN (SELECT id_anterior FROM equipo_info_fusion);
+DELETE FROM equipo_info_fusion;
+INSERT INTO equipo_info_fusion (id_anterior, id_nuevo)
+SELECT o.id, (SELECT n.id FROM equipo_info n WHERE n.mac_address = o.mac_address ORDER BY n.fecha_relevamiento DESC, n.id DESC LIMIT 1)
+FROM equipo_info o
+WHERE o.id <> (SELECT n.id FROM equipo_info n WHERE n.mac_address = o.mac_address ORDER BY n.fecha_relevamiento DESC, n.id DESC LIMIT 1);
+UPDATE equipo_historial SET equipo_id = (SELECT id_nuevo FROM equipo_info_fusion WHERE id_anterior = equipo_historial.equipo_id)
+WHERE equipo_id IN (SELECT id_anterior FROM equipo_info_fusion);
+UPDATE equipo_adapter SET equipo_id = (SELECT id_nuevo FROM equipo_info_fusion WHERE id_anterior = equipo_adapter.equipo_id)
+WHERE equipo_id IN (SELECT id_anterior FROM equipo_info_fusion);
+DELETE FROM equipo_info WHERE id IN (SELECT id_anterior FROM equipo_info_fusion);
+DELETE FROM equipo_info_fusion;
+DROP TABLE equipo_info_fusion;
+DROP INDEX idx_equipo_info_mac ON equipo_info;
+CREATE UNIQUE INDEX ux_equipo_info_mac ON equipo_info (mac_address);
+ALTER TABLE equipo_info ADD COLUMN serial_unico VARCHAR(100) AS (NULLIF(serial_number, '')) STORED;
+CREATE UNIQUE INDEX ux_equipo_info_serial ON equipo_info (serial_unico);
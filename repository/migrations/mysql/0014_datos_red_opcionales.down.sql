@@ -0,0 +1,8 @@
+UPDATE equipo_historial SET velocidad_mbps = 0 WHERE velocidad_mbps IS NULL;
+UPDATE equipo_historial SET dhcp_habilitado = 0 WHERE dhcp_habilitado IS NULL;
+ALTER TABLE equipo_historial MODIFY COLUMN velocidad_mbps INT NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial MODIFY COLUMN dhcp_habilitado TINYINT(1) NOT NULL DEFAULT 0;
+UPDATE equipo_info SET velocidad_mbps = 0 WHERE velocidad_mbps IS NULL;
+UPDATE equipo_info SET dhcp_habilitado = 0 WHERE dhcp_habilitado IS NULL;
+ALTER TABLE equipo_info MODIFY COLUMN velocidad_mbps INT NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info MODIFY COLUMN dhcp_habilitado TINYINT(1) NOT NULL DEFAULT 0;
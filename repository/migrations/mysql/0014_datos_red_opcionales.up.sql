@@ -0,0 +1,8 @@
+-- DHCP y velocidad pasan a admitir NULL para "no se pudo determinar".
+-- Una velocidad 0 nunca fue real, asi que pasa a NULL.
+ALTER TABLE equipo_info MODIFY COLUMN dhcp_habilitado TINYINT(1) NULL DEFAULT NULL;
+ALTER TABLE equipo_info MODIFY COLUMN velocidad_mbps INT NULL DEFAULT NULL;
+UPDATE equipo_info SET velocidad_mbps = NULL WHERE velocidad_mbps = 0;
+ALTER TABLE equipo_historial MODIFY COLUMN dhcp_habilitado TINYINT(1) NULL DEFAULT NULL;
+ALTER TABLE equipo_historial MODIFY COLUMN velocidad_mbps INT NULL DEFAULT NULL;
+UPDATE equipo_historial SET velocidad_mbps = NULL WHERE velocidad_mbps = 0;
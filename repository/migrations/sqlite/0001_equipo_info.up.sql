@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS equipo_info (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    fecha_relevamiento TEXT NOT NULL,
+    computer_name      TEXT NOT NULL,
+    nombre_anterior    TEXT,
+    mac_address        TEXT NOT NULL,
+    ip_address         TEXT,
+    piso               TEXT,
+    oficina            TEXT
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS equipo_historial;
+DROP INDEX IF EXISTS idx_equipo_info_serial;
+DROP INDEX IF EXISTS idx_equipo_info_mac;
+ALTER TABLE equipo_info DROP COLUMN serial_number;
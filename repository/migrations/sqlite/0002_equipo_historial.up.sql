@@ -0,0 +1,16 @@
+ALTER TABLE equipo_info ADD COLUMN serial_number TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_equipo_info_mac ON equipo_info (mac_address);
+CREATE INDEX IF NOT EXISTS idx_equipo_info_serial ON equipo_info (serial_number);
+CREATE TABLE IF NOT EXISTS equipo_historial (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    equipo_id          INTEGER NOT NULL REFERENCES equipo_info (id),
+    fecha_relevamiento TEXT NOT NULL,
+    computer_name      TEXT NOT NULL,
+    mac_address        TEXT NOT NULL,
+    ip_address         TEXT,
+    piso               TEXT,
+    oficina            TEXT,
+    serial_number      TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_equipo_historial_equipo ON equipo_historial (equipo_id);
+CREATE INDEX IF NOT EXISTS idx_equipo_historial_mac_fecha ON equipo_historial (mac_address, fecha_relevamiento);
@@ -0,0 +1,21 @@
+ALTER TABLE equipo_info ADD COLUMN sistema_operativo TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN version_so TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN arquitectura TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN memoria_ram TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN memoria_ram_mb INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN procesador TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN usuario_actual TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN fabricante TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN modelo TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN bios_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN sistema_operativo TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN version_so TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN arquitectura TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN memoria_ram TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN memoria_ram_mb INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN procesador TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN usuario_actual TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN fabricante TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN modelo TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN bios_version TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_equipo_info_modelo ON equipo_info (modelo);
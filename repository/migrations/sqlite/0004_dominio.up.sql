@@ -0,0 +1,7 @@
+ALTER TABLE equipo_info ADD COLUMN en_dominio INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN nombre_dominio TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN es_mec_local INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN en_dominio INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN nombre_dominio TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN es_mec_local INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_equipo_info_piso_dominio ON equipo_info (piso, en_dominio);
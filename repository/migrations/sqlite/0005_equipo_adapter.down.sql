@@ -0,0 +1 @@
+DROP TABLE IF EXISTS equipo_adapter;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS equipo_adapter (
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    equipo_id          INTEGER NOT NULL REFERENCES equipo_info (id),
+    historial_id       INTEGER NOT NULL REFERENCES equipo_historial (id),
+    fecha_relevamiento TEXT NOT NULL,
+    nombre             TEXT NOT NULL,
+    tipo               TEXT NOT NULL DEFAULT '',
+    mac_address        TEXT NOT NULL,
+    estado             TEXT NOT NULL DEFAULT '',
+    es_ethernet        INTEGER NOT NULL DEFAULT 0,
+    es_activo          INTEGER NOT NULL DEFAULT 0,
+    es_principal       INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_equipo_adapter_mac ON equipo_adapter (mac_address);
+CREATE INDEX IF NOT EXISTS idx_equipo_adapter_equipo ON equipo_adapter (equipo_id);
+CREATE INDEX IF NOT EXISTS idx_equipo_adapter_historial ON equipo_adapter (historial_id);
@@ -0,0 +1,5 @@
+ALTER TABLE equipo_adapter DROP COLUMN mac_aleatoria;
+ALTER TABLE equipo_adapter DROP COLUMN mac_local;
+ALTER TABLE equipo_adapter DROP COLUMN fabricante;
+ALTER TABLE equipo_historial DROP COLUMN mac_fabricante;
+ALTER TABLE equipo_info DROP COLUMN mac_fabricante;
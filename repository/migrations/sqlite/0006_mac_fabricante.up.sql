@@ -0,0 +1,8 @@
+ALTER TABLE equipo_info ADD COLUMN mac_fabricante TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN mac_fabricante TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_adapter ADD COLUMN fabricante TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_adapter ADD COLUMN mac_local INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_adapter ADD COLUMN mac_aleatoria INTEGER NOT NULL DEFAULT 0;
+UPDATE equipo_info SET mac_address = REPLACE(UPPER(TRIM(mac_address)), ':', '-');
+UPDATE equipo_historial SET mac_address = REPLACE(UPPER(TRIM(mac_address)), ':', '-');
+UPDATE equipo_adapter SET mac_address = REPLACE(UPPER(TRIM(mac_address)), ':', '-');
@@ -0,0 +1,4 @@
+ALTER TABLE equipo_info ADD COLUMN adaptador_principal TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN motivo_seleccion TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN adaptador_principal TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN motivo_seleccion TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE equipo_historial DROP COLUMN rango_red;
+ALTER TABLE equipo_info DROP COLUMN rango_red;
@@ -0,0 +1,2 @@
+ALTER TABLE equipo_historial DROP COLUMN edificio;
+ALTER TABLE equipo_info DROP COLUMN edificio;
@@ -0,0 +1,2 @@
+ALTER TABLE equipo_info ADD COLUMN edificio TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN edificio TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+ALTER TABLE equipo_info ADD COLUMN gateway TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN servidores_dns TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN dhcp_habilitado INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN servidor_dhcp TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_info ADD COLUMN lease_obtenido TEXT;
+ALTER TABLE equipo_info ADD COLUMN lease_expira TEXT;
+ALTER TABLE equipo_info ADD COLUMN velocidad_mbps INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_info ADD COLUMN duplex TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN gateway TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN servidores_dns TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN dhcp_habilitado INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN servidor_dhcp TEXT NOT NULL DEFAULT '';
+ALTER TABLE equipo_historial ADD COLUMN lease_obtenido TEXT;
+ALTER TABLE equipo_historial ADD COLUMN lease_expira TEXT;
+ALTER TABLE equipo_historial ADD COLUMN velocidad_mbps INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE equipo_historial ADD COLUMN duplex TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ux_equipo_historial_capture;
+ALTER TABLE equipo_historial DROP COLUMN capture_id;
@@ -0,0 +1,2 @@
+ALTER TABLE equipo_historial ADD COLUMN capture_id TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_equipo_historial_capture ON equipo_historial (capture_id);
@@ -0,0 +1 @@
+-- La forma canonica no se revierte: las notaciones originales no se guardaron.
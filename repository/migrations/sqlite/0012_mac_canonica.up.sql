@@ -0,0 +1,37 @@
+-- 0006 solo unifico las formas con ':' y '-'. Esta lleva aabb.ccdd.eeff y
+-- AABBCCDDEEFF a AA-BB-CC-DD-EE-FF, como core.ParseMAC.
+UPDATE equipo_info SET mac_address = UPPER(
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 1, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 3, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 5, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 7, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 9, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 11, 2))
+WHERE (LENGTH(TRIM(mac_address)) = 12
+       OR (LENGTH(TRIM(mac_address)) = 14 AND SUBSTR(TRIM(mac_address), 5, 1) = '.' AND SUBSTR(TRIM(mac_address), 10, 1) = '.'))
+  AND LENGTH(REPLACE(TRIM(mac_address), '.', '')) = 12
+  AND REPLACE(TRIM(mac_address), '.', '') NOT GLOB '*[^0-9A-Fa-f]*';
+
+UPDATE equipo_historial SET mac_address = UPPER(
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 1, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 3, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 5, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 7, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 9, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 11, 2))
+WHERE (LENGTH(TRIM(mac_address)) = 12
+       OR (LENGTH(TRIM(mac_address)) = 14 AND SUBSTR(TRIM(mac_address), 5, 1) = '.' AND SUBSTR(TRIM(mac_address), 10, 1) = '.'))
+  AND LENGTH(REPLACE(TRIM(mac_address), '.', '')) = 12
+  AND REPLACE(TRIM(mac_address), '.', '') NOT GLOB '*[^0-9A-Fa-f]*';
+
+UPDATE equipo_adapter SET mac_address = UPPER(
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 1, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 3, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 5, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 7, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 9, 2) || '-' ||
+    SUBSTR(REPLACE(TRIM(mac_address), '.', ''), 11, 2))
+WHERE (LENGTH(TRIM(mac_address)) = 12
+       OR (LENGTH(TRIM(mac_address)) = 14 AND SUBSTR(TRIM(mac_address), 5, 1) = '.' AND SUBSTR(TRIM(mac_address), 10, 1) = '.'))
+  AND LENGTH(REPLACE(TRIM(mac_address), '.', '')) = 12
+  AND REPLACE(TRIM(mac_address), '.', '') NOT GLOB '*[^0-9A-Fa-f]*';
@@ -0,0 +1,4 @@
+-- Las filas fusionadas no se recuperan; solo se vuelve a los indices comunes.
+DROP INDEX IF EXISTS ux_equipo_info_serial;
+DROP INDEX IF EXISTS ux_equipo_info_mac;
+CREATE INDEX IF NOT EXISTS idx_equipo_info_mac ON equipo_info (mac_address);
@@ -0,0 +1,16 @@
+ALTER TABLE equipo_historial ADD COLUMN velocidad_mbps_nuevo INTEGER NOT NULL DEFAULT 0;
+UPDATE equipo_historial SET velocidad_mbps_nuevo = COALESCE(velocidad_mbps, 0);
+ALTER TABLE equipo_historial DROP COLUMN velocidad_mbps;
+ALTER TABLE equipo_historial RENAME COLUMN velocidad_mbps_nuevo TO velocidad_mbps;
+ALTER TABLE equipo_historial ADD COLUMN dhcp_habilitado_nuevo INTEGER NOT NULL DEFAULT 0;
+UPDATE equipo_historial SET dhcp_habilitado_nuevo = COALESCE(dhcp_habilitado, 0);
+ALTER TABLE equipo_historial DROP COLUMN dhcp_habilitado;
+ALTER TABLE equipo_historial RENAME COLUMN dhcp_habilitado_nuevo TO dhcp_habilitado;
+ALTER TABLE equipo_info ADD COLUMN velocidad_mbps_nuevo INTEGER NOT NULL DEFAULT 0;
+UPDATE equipo_info SET velocidad_mbps_nuevo = COALESCE(velocidad_mbps, 0);
+ALTER TABLE equipo_info DROP COLUMN velocidad_mbps;
+ALTER TABLE equipo_info RENAME COLUMN velocidad_mbps_nuevo TO velocidad_mbps;
+ALTER TABLE equipo_info ADD COLUMN dhcp_habilitado_nuevo INTEGER NOT NULL DEFAULT 0;
+UPDATE equipo_info SET dhcp_habilitado_nuevo = COALESCE(dhcp_habilitado, 0);
+ALTER TABLE equipo_info DROP COLUMN dhcp_habilitado;
+ALTER TABLE equipo_info RENAME COLUMN dhcp_habilitado_nuevo TO dhcp_habilitado;
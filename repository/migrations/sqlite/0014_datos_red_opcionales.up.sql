@@ -0,0 +1,19 @@
+-- DHCP y velocidad pasan a admitir NULL para "no se pudo determinar".
+-- SQLite no cambia la nulabilidad de una columna: se copia a una nueva.
+-- Una velocidad 0 nunca fue real, asi que pasa a NULL.
+ALTER TABLE equipo_info ADD COLUMN dhcp_habilitado_nuevo INTEGER;
+UPDATE equipo_info SET dhcp_habilitado_nuevo = dhcp_habilitado;
+ALTER TABLE equipo_info DROP COLUMN dhcp_habilitado;
+ALTER TABLE equipo_info RENAME COLUMN dhcp_habilitado_nuevo TO dhcp_habilitado;
+ALTER TABLE equipo_info ADD COLUMN velocidad_mbps_nuevo INTEGER;
+UPDATE equipo_info SET velocidad_mbps_nuevo = NULLIF(velocidad_mbps, 0);
+ALTER TABLE equipo_info DROP COLUMN velocidad_mbps;
+ALTER TABLE equipo_info RENAME COLUMN velocidad_mbps_nuevo TO velocidad_mbps;
+ALTER TABLE equipo_historial ADD COLUMN dhcp_habilitado_nuevo INTEGER;
+UPDATE equipo_historial SET dhcp_habilitado_nuevo = dhcp_habilitado;
+ALTER TABLE equipo_historial DROP COLUMN dhcp_habilitado;
+ALTER TABLE equipo_historial RENAME COLUMN dhcp_habilitado_nuevo TO dhcp_habilitado;
+ALTER TABLE equipo_historial ADD COLUMN velocidad_mbps_nuevo INTEGER;
+UPDATE equipo_historial SET velocidad_mbps_nuevo = NULLIF(velocidad_mbps, 0);
+ALTER TABLE equipo_historial DROP COLUMN velocidad_mbps;
+ALTER TABLE equipo_historial RENAME COLUMN velocidad_mbps_nuevo TO velocidad_mbps;
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"database/sql"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// NewMySQLStore envuelve una conexion MySQL ya abierta y verificada.
+func NewMySQLStore(db *sql.DB) EquipoStore {
+	return &sqlStore{db: db, driver: DriverMySQL}
+}
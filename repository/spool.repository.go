@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	spoolVersion      = 1
+	spoolRejectedDir  = "rechazados"
+	spoolAlgoSHA256   = "sha256"
+	spoolAlgoHMAC     = "hmac-sha256"
+	spoolFileSuffix   = ".json"
+	spoolTempSuffix   = ".tmp"
+	spoolFileTimeSpec = "20060102T150405.000000000"
+)
+
+// SpoolEntry es una captura que no pudo guardarse en la base de datos y
+// queda en disco hasta el proximo sync. El checksum cubre el JSON de Equipo
+// tal como se escribio (compactado), no el struct, para que agregar campos a
+// EquipoInfo no invalide lo que ya esta en el spool. Se firma con HMAC
+// cuando hay clave configurada.
+type SpoolEntry struct {
+	Version   int             `json:"version"`
+	CaptureID string          `json:"capture_id"`
+	CreatedAt string          `json:"created_at"`
+	Motivo    string          `json:"motivo,omitempty"`
+	Equipo    json.RawMessage `json:"equipo"`
+	Algoritmo string          `json:"algoritmo"`
+	Checksum  string          `json:"checksum"`
+}
+
+// Spool administra el directorio de capturas pendientes.
+type Spool struct {
+	dir string
+	key []byte
+}
+
+type SyncReport struct {
+	Enviados   []string
+	Omitidos   []string
+	Rechazados []string
+	Pendientes []string
+}
+
+func NewSpool(dir string, key string) *Spool {
+	return &Spool{dir: dir, key: []byte(key)}
+}
+
+func (s *Spool) Dir() string {
+	return s.dir
+}
+
+// Enqueue guarda la captura en el spool y devuelve la ruta del archivo. El
+// capture_id de la entrada es el de equipo. Se escribe a un temporal y luego
+// se renombra para no dejar JSON a medias.
+func (s *Spool) Enqueue(equipo EquipoInfo, motivo string) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", fmt.Errorf("error creando directorio de spool: %v", err)
+	}
+
+	raw, err := json.Marshal(equipo)
+	if err != nil {
+		return "", fmt.Errorf("error serializando captura: %v", err)
+	}
+
+	now := time.Now()
+	entry := SpoolEntry{
+		Version:   spoolVersion,
+		CaptureID: equipo.CaptureID,
+		CreatedAt: now.Format(time.RFC3339),
+		Motivo:    motivo,
+		Equipo:    raw,
+	}
+	entry.Algoritmo, entry.Checksum, err = s.sign(entry.Equipo)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error serializando captura: %v", err)
+	}
+
+	name := fmt.Sprintf("%s_%s%s", now.UTC().Format(spoolFileTimeSpec), sanitizeFileName(equipo.ComputerName), spoolFileSuffix)
+	path := filepath.Join(s.dir, name)
+	tmpPath := path + spoolTempSuffix
+
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return "", fmt.Errorf("error escribiendo captura en spool: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("error guardando captura en spool: %v", err)
+	}
+
+	return path, nil
+}
+
+// Pending lista los archivos pendientes en orden de captura.
+func (s *Spool) Pending() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error leyendo spool: %v", err)
+	}
+
+	files := []string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), spoolFileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(s.dir, e.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Sync reenvia las capturas pendientes en orden. Una captura ya presente en
+// el historial se omite, por lo que repetir el sync no duplica registros.
+// Los archivos corruptos o con checksum invalido se mueven a rechazados/.
+// Ante un error de la base se detiene para respetar el orden.
+func (s *Spool) Sync(store EquipoStore) (*SyncReport, error) {
+	report := &SyncReport{}
+
+	files, err := s.Pending()
+	if err != nil {
+		return report, err
+	}
+
+	for i, path := range files {
+		name := filepath.Base(path)
+
+		entry, equipo, err := s.read(path)
+		if err != nil {
+			if rejectErr := s.reject(path); rejectErr != nil {
+				return report, rejectErr
+			}
+			report.Rechazados = append(report.Rechazados, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		if equipo.CaptureID == "" {
+			equipo.CaptureID = entry.CaptureID
+		}
+		exists, err := store.HasCaptura(equipo.CaptureID)
+		if err != nil {
+			report.Pendientes = append(report.Pendientes, baseNames(files[i:])...)
+			return report, fmt.Errorf("error verificando captura %s: %v", name, err)
+		}
+
+		if exists {
+			report.Omitidos = append(report.Omitidos, name)
+		} else {
+			result, err := store.Create(equipo)
+			if err != nil || !result.Success {
+				report.Pendientes = append(report.Pendientes, baseNames(files[i:])...)
+				if err == nil {
+					err = fmt.Errorf("%s", result.ErrorMessage)
+				}
+				return report, fmt.Errorf("error enviando captura %s: %w", name, err)
+			}
+			report.Enviados = append(report.Enviados, name)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return report, fmt.Errorf("error eliminando captura enviada %s: %v", name, err)
+		}
+	}
+
+	return report, nil
+}
+
+// read valida la firma de la entrada y decodifica el equipo.
+func (s *Spool) read(path string) (*SpoolEntry, EquipoInfo, error) {
+	var equipo EquipoInfo
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, equipo, fmt.Errorf("error leyendo archivo: %v", err)
+	}
+
+	var entry SpoolEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, equipo, fmt.Errorf("JSON invalido: %v", err)
+	}
+
+	if entry.Version != spoolVersion {
+		return nil, equipo, fmt.Errorf("version de spool no soportada: %d", entry.Version)
+	}
+	if entry.CaptureID == "" {
+		return nil, equipo, fmt.Errorf("falta capture_id")
+	}
+
+	algoritmo, checksum, err := s.sign(entry.Equipo)
+	if err != nil {
+		return nil, equipo, err
+	}
+	if entry.Algoritmo != algoritmo {
+		return nil, equipo, fmt.Errorf("algoritmo %q no coincide con la configuracion (%s)", entry.Algoritmo, algoritmo)
+	}
+	if !hmac.Equal([]byte(entry.Checksum), []byte(checksum)) {
+		return nil, equipo, fmt.Errorf("checksum invalido")
+	}
+
+	if err := json.Unmarshal(entry.Equipo, &equipo); err != nil {
+		return nil, equipo, fmt.Errorf("equipo invalido: %v", err)
+	}
+	return &entry, equipo, nil
+}
+
+func (s *Spool) reject(path string) error {
+	rejectedDir := filepath.Join(s.dir, spoolRejectedDir)
+	if err := os.MkdirAll(rejectedDir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio de rechazados: %v", err)
+	}
+	if err := os.Rename(path, filepath.Join(rejectedDir, filepath.Base(path))); err != nil {
+		return fmt.Errorf("error moviendo captura rechazada: %v", err)
+	}
+	return nil
+}
+
+// sign calcula el checksum del JSON compactado, que no cambia al indentar
+// el archivo.
+func (s *Spool) sign(equipo json.RawMessage) (string, string, error) {
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, equipo); err != nil {
+		return "", "", fmt.Errorf("equipo invalido: %v", err)
+	}
+
+	if len(s.key) > 0 {
+		mac := hmac.New(sha256.New, s.key)
+		mac.Write(payload.Bytes())
+		return spoolAlgoHMAC, hex.EncodeToString(mac.Sum(nil)), nil
+	}
+
+	sum := sha256.Sum256(payload.Bytes())
+	return spoolAlgoSHA256, hex.EncodeToString(sum[:]), nil
+}
+
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = filepath.Base(p)
+	}
+	return names
+}
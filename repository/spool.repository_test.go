@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// editEntry reescribe el JSON de una entrada del spool con la funcion dada.
+func editEntry(t *testing.T, path string, edit func(map[string]any)) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	edit(entry)
+	if data, err = json.Marshal(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpoolSignVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		writeKey  string
+		readKey   string
+		tamper    func(t *testing.T, path string)
+		rechazado string
+	}{
+		{name: "sha256 sin clave"},
+		{name: "hmac con la misma clave", writeKey: "clave", readKey: "clave"},
+		{name: "hmac con otra clave", writeKey: "clave", readKey: "otra", rechazado: "checksum invalido"},
+		{name: "firmado y leido sin clave", writeKey: "clave", rechazado: "algoritmo"},
+		{name: "sha256 leido con clave", readKey: "clave", rechazado: "algoritmo"},
+		{
+			name: "equipo modificado", writeKey: "clave", readKey: "clave", rechazado: "checksum invalido",
+			tamper: func(t *testing.T, path string) {
+				editEntry(t, path, func(e map[string]any) {
+					e["equipo"].(map[string]any)["oficina"] = "Otra"
+				})
+			},
+		},
+		{
+			name: "version desconocida", rechazado: "version de spool",
+			tamper: func(t *testing.T, path string) {
+				editEntry(t, path, func(e map[string]any) { e["version"] = 99 })
+			},
+		},
+		{
+			name: "JSON truncado", rechazado: "JSON invalido",
+			tamper: func(t *testing.T, path string) {
+				if err := os.Truncate(path, 20); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			equipo := testEquipo("AA-BB-CC-00-00-01", "SN-1", "2024-03-01 10:00:00")
+			equipo.CaptureID = "c0ffee"
+			path, err := NewSpool(dir, tt.writeKey).Enqueue(equipo, "sin conexion")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(t, path)
+			}
+
+			store := NewMemoryStore()
+			report, err := NewSpool(dir, tt.readKey).Sync(store)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.rechazado == "" {
+				if len(report.Enviados) != 1 || len(report.Rechazados) != 0 {
+					t.Fatalf("report = %+v, se esperaba un envio", report)
+				}
+				return
+			}
+
+			if len(report.Rechazados) != 1 || !strings.Contains(report.Rechazados[0], tt.rechazado) {
+				t.Fatalf("Rechazados = %v, se esperaba %q", report.Rechazados, tt.rechazado)
+			}
+			if _, err := os.Stat(filepath.Join(dir, spoolRejectedDir, filepath.Base(path))); err != nil {
+				t.Errorf("la captura no se movio a %s: %v", spoolRejectedDir, err)
+			}
+			if found, _ := store.FindByMac("AA-BB-CC-00-00-01"); len(found) != 0 {
+				t.Error("una captura rechazada llego a la base")
+			}
+		})
+	}
+}
+
+// TestSpoolChecksumUsesWrittenJSON simula un archivo escrito por una version
+// anterior, sin campos que EquipoInfo tiene ahora: la firma sigue valiendo
+// porque cubre el JSON guardado y no el struct.
+func TestSpoolChecksumUsesWrittenJSON(t *testing.T) {
+	dir := t.TempDir()
+	spool := NewSpool(dir, "clave")
+
+	raw := json.RawMessage(`{"fecha_relevamiento":"2024-03-01 10:00:00","mac_address":"AA-BB-CC-00-00-01","oficina":"Compras"}`)
+	algoritmo, checksum, err := spool.sign(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.MarshalIndent(SpoolEntry{
+		Version:   spoolVersion,
+		CaptureID: "c0ffee",
+		Equipo:    raw,
+		Algoritmo: algoritmo,
+		Checksum:  checksum,
+	}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "anterior.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMemoryStore()
+	report, err := spool.Sync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Enviados) != 1 {
+		t.Fatalf("report = %+v, se esperaba un envio", report)
+	}
+	if ok, _ := store.HasCaptura("c0ffee"); !ok {
+		t.Error("la captura no tomo el capture_id de la entrada")
+	}
+}
+
+func TestSpoolSyncSkipsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	spool := NewSpool(dir, "clave")
+
+	registrada := testEquipo("AA-BB-CC-00-00-01", "SN-1", "2024-03-01 10:00:00")
+	registrada.CaptureID = "aaaa0001"
+	// Otra captura del mismo equipo en el mismo segundo no es un duplicado.
+	nueva := registrada
+	nueva.CaptureID = "aaaa0002"
+
+	for _, equipo := range []EquipoInfo{registrada, nueva} {
+		path, err := spool.Enqueue(equipo, "sin conexion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, decoded, err := spool.read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.CaptureID != equipo.CaptureID || decoded.CaptureID != equipo.CaptureID || entry.Motivo != "sin conexion" {
+			t.Errorf("entrada = %+v, se esperaba el capture_id y el motivo recibidos", entry)
+		}
+	}
+
+	store := NewMemoryStore()
+	if _, err := store.Create(registrada); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := spool.Sync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Omitidos) != 1 || len(report.Enviados) != 1 {
+		t.Errorf("report = %+v, se esperaba una omitida y una enviada", report)
+	}
+	if ok, _ := store.HasCaptura("aaaa0002"); !ok {
+		t.Error("la captura del mismo segundo no llego a la base")
+	}
+	if pending, _ := spool.Pending(); len(pending) != 0 {
+		t.Errorf("quedaron %d capturas pendientes", len(pending))
+	}
+}
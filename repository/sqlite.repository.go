@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// OpenSQLiteDB abre (o crea) el archivo SQLite sin aplicar migraciones.
+func OpenSQLiteDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo base SQLite: %v", err)
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error configurando SQLite: %v", err)
+	}
+
+	return db, nil
+}
+
+// OpenSQLiteStore abre (o crea) una base SQLite local para oficinas sin
+// servidor de base de datos. Al ser local se migra automaticamente.
+func OpenSQLiteStore(path string) (EquipoStore, error) {
+	db, err := OpenSQLiteDB(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrando base SQLite: %v", err)
+	}
+
+	return &sqlStore{db: db, driver: DriverSQLite}, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EquipoStore abstrae el almacenamiento de los relevamientos para que la
+// captura pueda funcionar contra MySQL, SQLite embebido o memoria.
+type EquipoStore interface {
+	Create(equipo EquipoInfo) (*EquipoResult, error)
+	GetByID(id int64) (*EquipoVerificado, error)
+	FindByMac(macAddress string) ([]EquipoVerificado, error)
+	FindByComputerName(computerName string) ([]EquipoVerificado, error)
+	ListByUbicacion(piso, oficina string) ([]EquipoVerificado, error)
+	ListHistorial(equipoID int64) ([]HistorialEntry, error)
+	FindAdaptersByMac(macAddress string) ([]EquipoAdapter, error)
+	ListAdaptadores(equipoID int64) ([]EquipoAdapter, error)
+	HasCaptura(captureID string) (bool, error)
+	ResumenDominioPorPiso() ([]DominioResumen, error)
+	Close() error
+}
+
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+	DriverMemory = "memory"
+)
+
+var ErrEquipoNoEncontrado = errors.New("equipo no encontrado")
+
+// ErrVerificacion indica que el registro se escribio pero no pudo leerse de
+// vuelta dentro de la misma transaccion.
+var ErrVerificacion = errors.New("no se pudo verificar el registro guardado")
+
+func ValidateDriver(driver string) error {
+	switch driver {
+	case DriverMySQL, DriverSQLite, DriverMemory:
+		return nil
+	}
+	return fmt.Errorf("driver de base de datos no soportado: %s", driver)
+}